@@ -1,6 +1,7 @@
 package app
 
 import (
+	"errors"
 	"fmt"
 	"io/fs"
 
@@ -45,12 +46,28 @@ func (l *loader) Load() (state *State, err error) {
 	l.imports.AddNamed("log", "github.com/livebud/bud/package/log")
 	l.imports.AddNamed("budhttp", "github.com/livebud/bud/package/budhttp")
 	l.imports.Add(l.module.Import("bud/internal/web"))
+	state.Command = l.loadCommand()
 	state.Provider = l.loadProvider()
 	state.Flag = l.flag
 	state.Imports = l.imports.List()
 	return state, nil
 }
 
+// Load the custom commands, if there are any
+func (l *loader) loadCommand() *imports.Import {
+	if err := vfs.Exist(l.fsys, "bud/internal/command/command.go"); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		l.Bail(err)
+	}
+	importPath := l.module.Import("bud/internal/command")
+	return &imports.Import{
+		Name: l.imports.Add(importPath),
+		Path: importPath,
+	}
+}
+
 func (l *loader) loadProvider() *di.Provider {
 	jsVM := di.ToType("github.com/livebud/bud/package/js", "VM")
 	// TODO: the public generator should be able to configure this
@@ -67,6 +84,7 @@ func (l *loader) loadProvider() *di.Provider {
 			{Import: "github.com/livebud/bud/package/budhttp", Type: "Client"},
 			{Import: "github.com/livebud/bud/package/remotefs", Type: "*Client"},
 			{Import: "context", Type: "Context"},
+			{Import: "github.com/livebud/bud/package/js/v8", Type: "*Pool"},
 		},
 		Results: []di.Dependency{
 			di.ToType(l.module.Import("bud/internal/web"), "*Server"),
@@ -80,7 +98,8 @@ func (l *loader) loadProvider() *di.Provider {
 		},
 	}
 	if l.flag.Embed {
-		fn.Aliases[jsVM] = di.ToType("github.com/livebud/bud/package/js/v8", "*VM")
+		// Render views in parallel on a pool of V8 isolates
+		fn.Aliases[jsVM] = di.ToType("github.com/livebud/bud/package/js/v8", "*Pool")
 		fn.Aliases[publicFS] = di.ToType(l.module.Import("bud/internal/web/public"), "FS")
 		fn.Aliases[viewFS] = di.ToType(l.module.Import("bud/internal/web/view"), "FS")
 	}
@@ -93,5 +112,10 @@ func (l *loader) loadProvider() *di.Provider {
 	for _, imp := range provider.Imports {
 		l.imports.AddNamed(imp.Name, imp.Path)
 	}
+	// The pool size defaults to the number of CPUs and the pool is configured
+	// with a timeout
+	if provider.Variable("github.com/livebud/bud/package/js/v8.*Pool") != "" {
+		l.imports.AddStd("runtime", "time")
+	}
 	return provider
 }
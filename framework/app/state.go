@@ -10,4 +10,5 @@ type State struct {
 	Imports  []*imports.Import
 	Provider *di.Provider
 	Flag     *framework.Flag
+	Command  *imports.Import // Custom commands, if any
 }
@@ -0,0 +1,47 @@
+package command
+
+import (
+	_ "embed"
+	"fmt"
+
+	"github.com/livebud/bud/internal/gotemplate"
+	"github.com/livebud/bud/package/di"
+	"github.com/livebud/bud/package/genfs"
+	"github.com/livebud/bud/package/gomod"
+	"github.com/livebud/bud/package/parser"
+)
+
+//go:embed command.gotext
+var template string
+
+var generator = gotemplate.MustParse("framework/command/command.gotext", template)
+
+// Generate the command template from state
+func Generate(state *State) ([]byte, error) {
+	return generator.Generate(state)
+}
+
+// New command generator
+func New(injector *di.Injector, module *gomod.Module, parser *parser.Parser) *Generator {
+	return &Generator{injector, module, parser}
+}
+
+// Generator for custom commands
+type Generator struct {
+	injector *di.Injector
+	module   *gomod.Module
+	parser   *parser.Parser
+}
+
+func (g *Generator) GenerateFile(fsys genfs.FS, file *genfs.File) error {
+	state, err := Load(fsys, g.injector, g.module, g.parser)
+	if err != nil {
+		return fmt.Errorf("command: unable to load. %w", err)
+	}
+	code, err := Generate(state)
+	if err != nil {
+		return err
+	}
+	file.Data = code
+	return nil
+}
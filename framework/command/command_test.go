@@ -0,0 +1,114 @@
+package command_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/livebud/bud/internal/is"
+	"github.com/livebud/bud/internal/testcli"
+	"github.com/livebud/bud/internal/testdir"
+)
+
+func TestNoCommands(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	td := testdir.New(dir)
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(dir)
+	result, err := cli.Run(ctx, "build")
+	is.NoErr(err)
+	is.Equal(result.Stdout(), "")
+	is.NoErr(td.NotExists("bud/internal/command/command.go"))
+}
+
+func TestCommand(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	td := testdir.New(dir)
+	td.Files["command/hello/hello.go"] = `
+		package hello
+		import (
+			"context"
+			"fmt"
+		)
+		type Command struct {}
+		type Input struct {
+			Name  string ` + "`" + `arg:"name"` + "`" + `
+			Greeting string ` + "`" + `short:"g" default:"hello" help:"greeting to use"` + "`" + `
+			Shout bool
+		}
+		// say hello
+		func (c *Command) Hello(ctx context.Context, in *Input) error {
+			greeting := in.Greeting + " " + in.Name
+			if in.Shout {
+				greeting += "!"
+			}
+			fmt.Println(greeting)
+			return nil
+		}
+	`
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(dir)
+	result, err := cli.Run(ctx, "hello", "bud")
+	is.NoErr(err)
+	is.Equal(result.Stdout(), "hello bud\n")
+	is.NoErr(td.Exists("bud/internal/command/command.go"))
+}
+
+func TestNestedCommands(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	td := testdir.New(dir)
+	td.Files["command/admin/user/user.go"] = `
+		package user
+		import (
+			"fmt"
+		)
+		type Command struct {}
+		type Input struct {
+			Roles []string ` + "`" + `arg:"roles"` + "`" + `
+		}
+		// list the users
+		func (c *Command) List() {
+			fmt.Println("listing")
+		}
+		// create a user
+		func (c *Command) Create(in Input) error {
+			fmt.Println("creating", in.Roles)
+			return nil
+		}
+	`
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(dir)
+	result, err := cli.Run(ctx, "admin", "user", "list")
+	is.NoErr(err)
+	is.Equal(result.Stdout(), "listing\n")
+	result, err = cli.Run(ctx, "admin", "user", "create", "a", "b")
+	is.NoErr(err)
+	is.Equal(result.Stdout(), "creating [a b]\n")
+}
+
+func TestDuplicateCommand(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	td := testdir.New(dir)
+	td.Files["command/deploy/deploy.go"] = `
+		package deploy
+		type Command struct {}
+		func (c *Command) Deploy() {}
+	`
+	td.Files["command/command.go"] = `
+		package command
+		type Command struct {}
+		func (c *Command) Deploy() {}
+	`
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(dir)
+	_, err := cli.Run(ctx, "build")
+	is.True(err != nil)
+	is.In(err.Error(), `command: "deploy" is defined more than once`)
+}
@@ -0,0 +1,44 @@
+package commandrt
+
+import "strconv"
+
+// String sets an optional *string field. Empty values leave the field nil.
+func String(target **string) func(value string) error {
+	return func(value string) error {
+		if value == "" {
+			return nil
+		}
+		*target = &value
+		return nil
+	}
+}
+
+// Int sets an optional *int field. Empty values leave the field nil.
+func Int(target **int) func(value string) error {
+	return func(value string) error {
+		if value == "" {
+			return nil
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		*target = &n
+		return nil
+	}
+}
+
+// Bool sets an optional *bool field. Empty values leave the field nil.
+func Bool(target **bool) func(value string) error {
+	return func(value string) error {
+		if value == "" {
+			return nil
+		}
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		*target = &b
+		return nil
+	}
+}
@@ -0,0 +1,343 @@
+package command
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/livebud/bud/internal/bail"
+	"github.com/livebud/bud/internal/imports"
+	"github.com/livebud/bud/internal/valid"
+	"github.com/livebud/bud/package/di"
+	"github.com/livebud/bud/package/gomod"
+	"github.com/livebud/bud/package/parser"
+	"github.com/matthewmueller/gotext"
+)
+
+func Load(fsys fs.FS, injector *di.Injector, module *gomod.Module, parser *parser.Parser) (*State, error) {
+	if files, err := fs.Glob(fsys, "command/**.go"); err != nil {
+		return nil, err
+	} else if len(files) == 0 {
+		return nil, fs.ErrNotExist
+	}
+	loader := &loader{
+		fsys:     fsys,
+		imports:  imports.New(),
+		injector: injector,
+		module:   module,
+		parser:   parser,
+		usages:   map[string]string{},
+	}
+	state, err := loader.Load()
+	if err != nil {
+		return nil, err
+	}
+	// Don't generate anything if there aren't any commands to run
+	if len(state.Runs) == 0 {
+		return nil, fs.ErrNotExist
+	}
+	return state, nil
+}
+
+// loader struct
+type loader struct {
+	bail.Struct
+	fsys      fs.FS
+	injector  *di.Injector
+	imports   *imports.Set
+	module    *gomod.Module
+	parser    *parser.Parser
+	providers []*di.Provider
+	runs      []*Run
+	usages    map[string]string // usages for each command in the chain
+}
+
+// Load the command state
+func (l *loader) Load() (state *State, err error) {
+	defer l.Recover2(&err, "command: unable to load state")
+	state = new(State)
+	l.loadCommand("command")
+	state.Runs = l.loadChains(l.runs)
+	state.Providers = l.providers
+	l.imports.AddStd("context")
+	l.imports.AddNamed("commander", "github.com/livebud/bud/package/commander")
+	l.imports.AddNamed("log", "github.com/livebud/bud/package/log")
+	state.Imports = l.imports.List()
+	return state, nil
+}
+
+func (l *loader) loadCommand(commandDir string) {
+	des, err := fs.ReadDir(l.fsys, commandDir)
+	if err != nil {
+		l.Bail(err)
+	}
+	shouldParse := false
+	for _, de := range des {
+		if !de.IsDir() && valid.GoFile(de.Name()) {
+			shouldParse = true
+			continue
+		}
+		if de.IsDir() && valid.Dir(de.Name()) {
+			l.loadCommand(path.Join(commandDir, de.Name()))
+		}
+	}
+	if !shouldParse {
+		return
+	}
+	pkg, err := l.parser.Parse(commandDir)
+	if err != nil {
+		l.Bail(err)
+	}
+	stct := pkg.Struct("Command")
+	if stct == nil {
+		return
+	}
+	methods := stct.PublicMethods()
+	if len(methods) == 0 {
+		return
+	}
+	importPath, err := stct.File().Import()
+	if err != nil {
+		l.Bail(err)
+	}
+	l.imports.Add(importPath)
+	provider := l.loadProvider(commandDir, stct)
+	for _, method := range methods {
+		l.runs = append(l.runs, l.loadRun(commandDir, method, provider))
+	}
+}
+
+// Path to the command without the command/ prefix (e.g. admin/user)
+func commandPath(commandDir string) string {
+	return strings.TrimPrefix(strings.TrimPrefix(commandDir, "command"), "/")
+}
+
+func (l *loader) loadRun(commandDir string, method *parser.Function, provider *di.Provider) *Run {
+	run := new(Run)
+	run.Name = method.Name()
+	run.Provider = provider
+	dir := commandPath(commandDir)
+	slug := gotext.Slug(run.Name)
+	run.Key = path.Join(dir, slug)
+	// A method with the same name as the directory (e.g. deploy/Deploy) runs the
+	// directory's command, rather than becoming a subcommand.
+	if path.Base(dir) == slug {
+		run.Key = dir
+		l.usages[dir] = method.Doc()
+	} else {
+		l.usages[run.Key] = method.Doc()
+	}
+	for _, param := range method.Params() {
+		run.Params = append(run.Params, l.loadParam(run, param))
+	}
+	run.HasError = l.loadHasError(commandDir, method)
+	return run
+}
+
+func (l *loader) loadParam(run *Run, param *parser.Param) *Param {
+	isContext, err := parser.IsImportType(param.Type(), "context", "Context")
+	if err != nil {
+		l.Bail(err)
+	}
+	if isContext {
+		return &Param{Variable: "ctx"}
+	}
+	def, err := param.Definition()
+	if err != nil {
+		l.Bail(fmt.Errorf("command: unable to find param definition for %s. %w", param.Type(), err))
+	}
+	if def.Kind() != parser.KindStruct || run.Input != nil {
+		l.Bail(fmt.Errorf("command: %s can only accept a context and an input struct, got %s", run.Key, param.Type()))
+	}
+	run.Input = l.loadInput(run, param, def)
+	if run.Input.Pointer {
+		return &Param{Variable: "in"}
+	}
+	return &Param{Variable: "*in"}
+}
+
+func (l *loader) loadHasError(commandDir string, method *parser.Function) bool {
+	results := method.Results()
+	switch len(results) {
+	case 0:
+		return false
+	case 1:
+		if results[0].IsError() {
+			return true
+		}
+	}
+	l.Bail(fmt.Errorf("command: %s.%s must return an error or nothing", commandDir, method.Name()))
+	return false
+}
+
+func (l *loader) loadInput(run *Run, param *parser.Param, def parser.Declaration) *Input {
+	stct := def.Package().Struct(def.Name())
+	if stct == nil {
+		l.Bail(fmt.Errorf("command: unable to find struct for %s", param.Type()))
+	}
+	importPath, err := def.Package().Import()
+	if err != nil {
+		l.Bail(err)
+	}
+	input := new(Input)
+	input.Type = l.imports.Add(importPath) + "." + def.Name()
+	input.Pointer = strings.HasPrefix(param.Type().String(), "*")
+	for _, field := range stct.PublicFields() {
+		tags, err := field.Tags()
+		if err != nil {
+			l.Bail(fmt.Errorf("command: unable to parse tags for %s.%s. %w", def.Name(), field.Name(), err))
+		}
+		value := l.loadValue(run, field, tags)
+		if tags.Has("arg") {
+			// Positional args are strings, ints, maps or pointers to these types
+			if value.Method == "Bool" {
+				l.Bail(fmt.Errorf("command: %s in %s can't be a bool argument", field.Name(), run.Key))
+			}
+			input.Args = append(input.Args, &Arg{value})
+			continue
+		}
+		input.Flags = append(input.Flags, &Flag{
+			Value: value,
+			Short: l.loadShort(run, field, tags.Get("short")),
+			Usage: strconv.Quote(tags.Get("help")),
+		})
+	}
+	return input
+}
+
+func (l *loader) loadShort(run *Run, field *parser.Field, short string) string {
+	if short == "" {
+		return ""
+	}
+	if len(short) != 1 {
+		l.Bail(fmt.Errorf("command: short flag %q for %s in %s must be a single character", short, field.Name(), run.Key))
+	}
+	return strconv.QuoteRune(rune(short[0]))
+}
+
+func (l *loader) loadValue(run *Run, field *parser.Field, tags parser.Tags) *Value {
+	value := new(Value)
+	value.Field = field.Name()
+	value.Name = tags.Get("arg")
+	if value.Name == "" {
+		value.Name = gotext.Slug(field.Name())
+	}
+	dataType := field.Type().String()
+	switch dataType {
+	case "string":
+		value.Method = "String"
+	case "int":
+		value.Method = "Int"
+	case "bool":
+		value.Method = "Bool"
+		// Boolean flags default to false
+		value.Default = "false"
+	case "[]string":
+		value.Method = "Strings"
+		value.Optional = true
+	case "map[string]string":
+		value.Method = "StringMap"
+		value.Optional = true
+	case "*string", "*int", "*bool":
+		value.Method = "Custom"
+		value.Pointer = gotext.Pascal(strings.TrimPrefix(dataType, "*"))
+		value.Optional = true
+		l.imports.AddNamed("commandrt", "github.com/livebud/bud/framework/command/commandrt")
+	default:
+		l.Bail(fmt.Errorf("command: unsupported type %s for %s in %s", dataType, field.Name(), run.Key))
+	}
+	if tags.Has("default") {
+		value.Default = l.loadDefault(run, field, value, tags.Get("default"))
+		value.Optional = false
+	}
+	return value
+}
+
+// Turn the default tag into a Go expression
+func (l *loader) loadDefault(run *Run, field *parser.Field, value *Value, defval string) string {
+	switch value.Method {
+	case "String", "Custom":
+		return strconv.Quote(defval)
+	case "Int":
+		if _, err := strconv.Atoi(defval); err != nil {
+			l.Bail(fmt.Errorf("command: invalid default %q for %s in %s. %w", defval, field.Name(), run.Key, err))
+		}
+		return defval
+	case "Bool":
+		b, err := strconv.ParseBool(defval)
+		if err != nil {
+			l.Bail(fmt.Errorf("command: invalid default %q for %s in %s. %w", defval, field.Name(), run.Key, err))
+		}
+		return strconv.FormatBool(b)
+	case "Strings":
+		values := strings.Split(defval, ",")
+		for i, v := range values {
+			values[i] = strconv.Quote(strings.TrimSpace(v))
+		}
+		return strings.Join(values, ", ")
+	default:
+		l.Bail(fmt.Errorf("command: default values aren't supported for %s in %s", field.Name(), run.Key))
+		return ""
+	}
+}
+
+// Load the chain of commands leading up to each run. This happens after all
+// the runs have been loaded so each command in the chain has the same usage.
+func (l *loader) loadChains(runs []*Run) []*Run {
+	seen := map[string]bool{}
+	for _, run := range runs {
+		if seen[run.Key] {
+			l.Bail(fmt.Errorf("command: %q is defined more than once", run.Key))
+		}
+		seen[run.Key] = true
+		names := strings.Split(run.Key, "/")
+		for i, name := range names {
+			key := strings.Join(names[:i+1], "/")
+			run.Chain = append(run.Chain, &Segment{
+				Name:  name,
+				Usage: strconv.Quote(l.usages[key]),
+			})
+		}
+	}
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].Key < runs[j].Key
+	})
+	return runs
+}
+
+func (l *loader) loadProvider(commandDir string, stct *parser.Struct) *di.Provider {
+	importPath, err := stct.File().Import()
+	if err != nil {
+		l.Bail(err)
+	}
+	fnName := gotext.Camel("load " + gotext.Space(commandPath(commandDir)) + " " + stct.Name())
+	provider, err := l.injector.Wire(&di.Function{
+		Name:    fnName,
+		Target:  l.module.Import("bud", "internal", "command"),
+		Imports: l.imports,
+		Results: []di.Dependency{
+			&di.Type{
+				Import: importPath,
+				Type:   "*" + stct.Name(),
+			},
+			&di.Error{},
+		},
+		Params: []*di.Param{
+			{Import: "context", Type: "Context"},
+			{Import: "github.com/livebud/bud/package/log", Type: "Log"},
+		},
+		Aliases: di.Aliases{},
+	})
+	if err != nil {
+		l.Bail(err)
+	}
+	// Add generated imports
+	for _, imp := range provider.Imports {
+		l.imports.AddNamed(imp.Name, imp.Path)
+	}
+	l.providers = append(l.providers, provider)
+	return provider
+}
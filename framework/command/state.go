@@ -0,0 +1,65 @@
+package command
+
+import (
+	"github.com/livebud/bud/internal/imports"
+	"github.com/livebud/bud/package/di"
+)
+
+type State struct {
+	Imports   []*imports.Import
+	Runs      []*Run
+	Providers []*di.Provider
+}
+
+// Run is a public method on a command's Command struct. Each run becomes a
+// subcommand in the CLI.
+type Run struct {
+	Name     string     // Name of the method (e.g. List)
+	Key      string     // Path to the subcommand (e.g. admin/user/list)
+	Chain    []*Segment // Commands leading up to this run
+	Provider *di.Provider
+	Input    *Input
+	Params   []*Param
+	HasError bool
+}
+
+// Segment is a command in the chain (e.g. admin)
+type Segment struct {
+	Name  string
+	Usage string // Quoted usage string
+}
+
+// Input is the struct that's passed into the method
+type Input struct {
+	Type    string // e.g. user.Filter
+	Pointer bool
+	Flags   []*Flag
+	Args    []*Arg
+}
+
+// Param is a variable that's passed into the method
+type Param struct {
+	Variable string
+}
+
+// Value is a field in the input struct that's set from the command line
+type Value struct {
+	Name     string // Name of the flag or arg (e.g. since)
+	Field    string // Name of the struct field (e.g. Since)
+	Method   string // Commander method (e.g. Int)
+	Default  string // Default value as a Go expression (e.g. 0)
+	Optional bool
+	Pointer  string // Element type when the field is a pointer (e.g. string)
+}
+
+// Flag is a field that becomes a --flag
+type Flag struct {
+	*Value
+	Short string // Quoted short flag (e.g. 's')
+	Usage string // Quoted usage string
+}
+
+// Arg is a field that becomes a positional <arg>
+type Arg struct {
+	*Value
+}
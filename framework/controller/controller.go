@@ -6,11 +6,16 @@ import (
 
 	_ "embed"
 	"fmt"
+	"io/fs"
+	"strings"
 
+	"github.com/livebud/bud/framework/routes"
+	"github.com/livebud/bud/internal/errs"
 	"github.com/livebud/bud/internal/gotemplate"
 	"github.com/livebud/bud/package/di"
 	"github.com/livebud/bud/package/genfs"
 	"github.com/livebud/bud/package/gomod"
+	"github.com/livebud/bud/package/log"
 	"github.com/livebud/bud/package/parser"
 )
 
@@ -25,13 +30,14 @@ func Generate(state *State) ([]byte, error) {
 }
 
 // New controller generator
-func New(injector *di.Injector, module *gomod.Module, parser *parser.Parser) *Generator {
-	return &Generator{injector, module, parser}
+func New(injector *di.Injector, log log.Log, module *gomod.Module, parser *parser.Parser) *Generator {
+	return &Generator{injector, log, module, parser}
 }
 
 // Generator for controllers
 type Generator struct {
 	injector *di.Injector
+	log      log.Log
 	module   *gomod.Module
 	parser   *parser.Parser
 }
@@ -41,6 +47,9 @@ func (g *Generator) GenerateFile(fsys genfs.FS, file *genfs.File) error {
 	if err != nil {
 		return fmt.Errorf("controller: unable to load. %w", err)
 	}
+	if err := g.checkRoutes(fsys, state); err != nil {
+		return err
+	}
 	code, err := Generate(state)
 	if err != nil {
 		return err
@@ -48,3 +57,42 @@ func (g *Generator) GenerateFile(fsys genfs.FS, file *genfs.File) error {
 	file.Data = code
 	return nil
 }
+
+// Check the routes from the controllers, views and public files for conflicts
+// before they're registered. Routes that can't be told apart fail the build,
+// while shadowed routes are logged as warnings.
+func (g *Generator) checkRoutes(fsys fs.FS, state *State) error {
+	routeState, err := routes.Load(fsys, actionRoutes(state.Controller))
+	if err != nil {
+		return err
+	}
+	var conflicts []error
+	for _, conflict := range routes.Check(routeState.Routes) {
+		if conflict.Shadow {
+			g.log.Warn("%s", conflict)
+			continue
+		}
+		conflicts = append(conflicts, conflict)
+	}
+	return errs.Join(conflicts...)
+}
+
+// actionRoutes lists the routes of the controller's actions and the actions of
+// its nested controllers
+func actionRoutes(controller *Controller) (list []*routes.Route) {
+	for _, action := range controller.Actions {
+		route := &routes.Route{
+			Method: strings.ToUpper(action.Method),
+			Route:  action.Route,
+			Action: action.Key,
+		}
+		if action.View != nil {
+			route.View = action.View.Path
+		}
+		list = append(list, route)
+	}
+	for _, controller := range controller.Controllers {
+		list = append(list, actionRoutes(controller)...)
+	}
+	return list
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -13,6 +14,7 @@ import (
 	"github.com/livebud/bud/internal/testcli"
 	"github.com/livebud/bud/internal/testdir"
 	"github.com/livebud/bud/internal/versions"
+	"github.com/livebud/bud/package/websocket"
 	"github.com/matthewmueller/diff"
 )
 
@@ -1829,13 +1831,14 @@ func TestHandlerFuncs(t *testing.T) {
 		package controller
 		import "io"
 		import "net/http"
+		import "github.com/livebud/bud/package/router"
 		type Controller struct {}
 		func (c *Controller) Index() string {
 			return "hello"
 		}
 		func (c *Controller) Create(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(http.StatusCreated)
-			w.Write([]byte(r.URL.Query().Get("foo_id")))
+			w.Write([]byte(router.Params(r).Get("foo_id")))
 			io.Copy(w, r.Body)
 		}
 	`
@@ -3071,3 +3074,601 @@ func TestUpdateBodyAndSignatureAndRoute(t *testing.T) {
 	`))
 	is.In(res.Body().String(), `/10`)
 }
+
+func TestRouteAnnotation(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	td := testdir.New(dir)
+	td.Files["controller/sessions/controller.go"] = `
+		package sessions
+		type Controller struct {}
+		// Destroy the session
+		// @route POST /sessions/destroy
+		func (c *Controller) Destroy() string {
+			return "destroyed"
+		}
+		// @route GET legacy
+		func (c *Controller) Legacy() string {
+			return "legacy"
+		}
+	`
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(dir)
+	app, err := cli.Start(ctx, "run")
+	is.NoErr(err)
+	defer app.Close()
+	res, err := app.PostJSON("/sessions/destroy", nil)
+	is.NoErr(err)
+	is.NoErr(res.Diff(`
+		HTTP/1.1 200 OK
+		Content-Type: application/json
+
+		"destroyed"
+	`))
+	res, err = app.GetJSON("/sessions/legacy")
+	is.NoErr(err)
+	is.NoErr(res.Diff(`
+		HTTP/1.1 200 OK
+		Content-Type: application/json
+
+		"legacy"
+	`))
+	// The default route is no longer registered, but the path still exists
+	// under the annotation's method
+	res, err = app.Get("/sessions/destroy")
+	is.NoErr(err)
+	is.Equal(res.Status(), 405)
+	is.Equal(res.Header("Allow"), "OPTIONS, POST")
+	is.NoErr(app.Close())
+}
+
+func TestDuplicateRoute(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	td := testdir.New(dir)
+	td.Files["controller/users/controller.go"] = `
+		package users
+		type Controller struct {}
+		func (c *Controller) Create() {}
+		// @route POST /users
+		func (c *Controller) Signup() {}
+	`
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(dir)
+	_, err := cli.Run(ctx, "build")
+	is.True(err != nil)
+	is.In(err.Error(), `controller: "/users/create" and "/users/signup" both map to "POST /users"`)
+}
+
+func TestDuplicateRouteSlotNames(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	td := testdir.New(dir)
+	td.Files["controller/posts/controller.go"] = `
+		package posts
+		type Controller struct {}
+		func (c *Controller) Show(id string) {}
+		// @route GET /posts/:post_id
+		func (c *Controller) Preview(postID string) {}
+	`
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(dir)
+	_, err := cli.Run(ctx, "build")
+	is.True(err != nil)
+	is.In(err.Error(), `controller: "/posts/show" and "/posts/preview" both map to "GET /posts/:post_id"`)
+}
+
+func TestValidateInput(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	td := testdir.New(dir)
+	td.Files["controller/users/controller.go"] = `
+		package users
+		type Controller struct {}
+		type User struct {
+			Name  string ` + "`" + `json:"name" validate:"required"` + "`" + `
+			Email string ` + "`" + `json:"email" validate:"email"` + "`" + `
+		}
+		func (c *Controller) Create(in *User) *User {
+			return in
+		}
+	`
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(dir)
+	app, err := cli.Start(ctx, "run")
+	is.NoErr(err)
+	defer app.Close()
+	res, err := app.PostJSON("/users", bytes.NewBufferString(`{"email":"nope"}`))
+	is.NoErr(err)
+	is.NoErr(res.Diff(`
+		HTTP/1.1 422 Unprocessable Entity
+		Content-Type: application/json
+
+		{"error":"request: invalid input. email must be a valid email address. name is required","fields":{"email":["must be a valid email address"],"name":["is required"]}}
+	`))
+	res, err = app.PostJSON("/users", bytes.NewBufferString(`{"name":"Ann","email":"ann@example.com"}`))
+	is.NoErr(err)
+	is.NoErr(res.Diff(`
+		HTTP/1.1 200 OK
+		Content-Type: application/json
+
+		{"name":"Ann","email":"ann@example.com"}
+	`))
+	// HTML requests are redirected back
+	req, err := app.PostRequest("/users", bytes.NewBufferString(`email=nope`))
+	is.NoErr(err)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "text/html")
+	req.Header.Set("Referer", "/users/new")
+	res, err = app.Do(req)
+	is.NoErr(err)
+	is.Equal(res.Status(), 303)
+	is.Equal(res.Header("Location"), "/users/new")
+	is.In(res.Header("Set-Cookie"), "bud_errors=")
+	is.NoErr(app.Close())
+}
+
+func TestStatusErrors(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	td := testdir.New(dir)
+	td.Files["controller/posts/controller.go"] = `
+		package posts
+		import (
+			"fmt"
+			"github.com/livebud/bud/framework/controller/controllerrt/response"
+		)
+		type Controller struct {}
+		type forbidden struct{}
+		func (forbidden) Error() string { return "not yours" }
+		func (forbidden) StatusCode() int { return 403 }
+		func (c *Controller) Show(id int) (string, error) {
+			return "", fmt.Errorf("post %d: %w", id, response.ErrNotFound)
+		}
+		func (c *Controller) Update(id int) error {
+			return forbidden{}
+		}
+		func (c *Controller) Delete(id int) error {
+			return fmt.Errorf("unable to delete")
+		}
+	`
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(dir)
+	app, err := cli.Start(ctx, "run")
+	is.NoErr(err)
+	defer app.Close()
+	res, err := app.GetJSON("/posts/10")
+	is.NoErr(err)
+	is.NoErr(res.Diff(`
+		HTTP/1.1 404 Not Found
+		Content-Type: application/json
+
+		{"error":"post 10: not found"}
+	`))
+	res, err = app.Get("/posts/10")
+	is.NoErr(err)
+	is.NoErr(res.Diff(`
+		HTTP/1.1 404 Not Found
+		Content-Type: text/plain; charset=utf-8
+		X-Content-Type-Options: nosniff
+
+		post 10: not found
+	`))
+	res, err = app.PatchJSON("/posts/10", nil)
+	is.NoErr(err)
+	is.NoErr(res.Diff(`
+		HTTP/1.1 403 Forbidden
+		Content-Type: application/json
+
+		{"error":"not yours"}
+	`))
+	res, err = app.DeleteJSON("/posts/10", nil)
+	is.NoErr(err)
+	is.NoErr(res.Diff(`
+		HTTP/1.1 500 Internal Server Error
+		Content-Type: application/json
+
+		{"error":"unable to delete"}
+	`))
+	is.NoErr(app.Close())
+}
+
+func TestErrorView(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	td := testdir.New(dir)
+	td.NodeModules["svelte"] = versions.Svelte
+	td.Files["view/Error.svelte"] = `
+		<script>
+			export let status = 500
+			export let message = ""
+		</script>
+		<h1>{status}: {message}</h1>
+	`
+	td.Files["view/posts/show.svelte"] = `
+		<script>
+			export let post = {}
+		</script>
+		<h1>{post.title}</h1>
+	`
+	td.Files["controller/posts/controller.go"] = `
+		package posts
+		import (
+			"github.com/livebud/bud/framework/controller/controllerrt/response"
+		)
+		type Controller struct {}
+		type Post struct {
+			Title string ` + "`" + `json:"title"` + "`" + `
+		}
+		func (c *Controller) Show(id int) (*Post, error) {
+			return nil, response.ErrNotFound
+		}
+	`
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(dir)
+	app, err := cli.Start(ctx, "run")
+	is.NoErr(err)
+	defer app.Close()
+	res, err := app.Get("/posts/10")
+	is.NoErr(err)
+	is.Equal(res.Status(), 404)
+	is.In(res.Body().String(), "<h1>404: not found</h1>")
+	is.NoErr(app.Close())
+}
+
+func TestControllerMiddleware(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	td := testdir.New(dir)
+	td.Files["controller/controller.go"] = `
+		package controller
+		type Controller struct {}
+		func (c *Controller) Index() string { return "home" }
+	`
+	td.Files["controller/admin/controller.go"] = `
+		package admin
+		import "net/http"
+		type Controller struct {}
+		func (c *Controller) Middleware(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Authorization") != "secret" {
+					http.Error(w, "unauthorized", http.StatusUnauthorized)
+					return
+				}
+				w.Header().Set("X-Admin", "true")
+				next.ServeHTTP(w, r)
+			})
+		}
+		func (c *Controller) Index() string { return "admin" }
+	`
+	td.Files["controller/admin/users/controller.go"] = `
+		package users
+		type Controller struct {}
+		func (c *Controller) Index() []string { return []string{"alice"} }
+	`
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(dir)
+	app, err := cli.Start(ctx, "run")
+	is.NoErr(err)
+	defer app.Close()
+	// Other controllers aren't wrapped
+	res, err := app.GetJSON("/")
+	is.NoErr(err)
+	is.Equal(res.Status(), 200)
+	is.Equal(res.Header("X-Admin"), "")
+	// The controller's actions are wrapped
+	res, err = app.GetJSON("/admin")
+	is.NoErr(err)
+	is.Equal(res.Status(), 401)
+	req, err := app.GetRequest("/admin")
+	is.NoErr(err)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", "secret")
+	res, err = app.Do(req)
+	is.NoErr(err)
+	is.Equal(res.Status(), 200)
+	is.Equal(res.Header("X-Admin"), "true")
+	// Nested controllers inherit the middleware
+	res, err = app.GetJSON("/admin/users")
+	is.NoErr(err)
+	is.Equal(res.Status(), 401)
+	req, err = app.GetRequest("/admin/users")
+	is.NoErr(err)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", "secret")
+	res, err = app.Do(req)
+	is.NoErr(err)
+	is.Equal(res.Status(), 200)
+	is.Equal(res.Header("X-Admin"), "true")
+	is.In(res.Body().String(), `["alice"]`)
+	is.NoErr(app.Close())
+}
+
+func TestControllerMiddlewareField(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	td := testdir.New(dir)
+	td.Files["limit/limit.go"] = `
+		package limit
+		import "net/http"
+		func New() *Limiter { return &Limiter{} }
+		type Limiter struct {}
+		func (l *Limiter) Middleware(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-Rate-Limit", "100")
+				next.ServeHTTP(w, r)
+			})
+		}
+	`
+	td.Files["controller/api/controller.go"] = `
+		package api
+		import "app.com/limit"
+		type Controller struct {
+			Limiter *limit.Limiter
+		}
+	`
+	td.Files["controller/api/posts/controller.go"] = `
+		package posts
+		type Controller struct {}
+		func (c *Controller) Index() []string { return []string{"hello"} }
+	`
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(dir)
+	app, err := cli.Start(ctx, "run")
+	is.NoErr(err)
+	defer app.Close()
+	res, err := app.GetJSON("/api/posts")
+	is.NoErr(err)
+	is.Equal(res.Status(), 200)
+	is.Equal(res.Header("X-Rate-Limit"), "100")
+	is.In(res.Body().String(), `["hello"]`)
+	is.NoErr(app.Close())
+}
+
+func TestSession(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	td := testdir.New(dir)
+	td.Files["middleware/middleware.go"] = `
+		package middleware
+		import (
+			"bytes"
+			"github.com/livebud/bud/package/middleware"
+			"github.com/livebud/bud/package/session"
+		)
+		type Stack []middleware.Middleware
+		func New() (Stack, error) {
+			store, err := session.NewCookieStore(bytes.Repeat([]byte("s"), 32))
+			if err != nil {
+				return nil, err
+			}
+			return Stack{session.Middleware(store)}, nil
+		}
+	`
+	td.Files["controller/controller.go"] = `
+		package controller
+		import (
+			"strings"
+			"github.com/livebud/bud/package/session"
+		)
+		type Controller struct {
+			Session *session.Session
+		}
+		func (c *Controller) Index() string {
+			return c.Session.Get("name") + ":" + strings.Join(c.Session.Flashes("notice"), ",")
+		}
+		func (c *Controller) Create(name string) {
+			c.Session.Set("name", name)
+			c.Session.Flash("notice", "created")
+		}
+	`
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(dir)
+	app, err := cli.Start(ctx, "run")
+	is.NoErr(err)
+	defer app.Close()
+	req, err := app.PostRequest("/", bytes.NewBufferString("name=alice"))
+	is.NoErr(err)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	res, err := app.Do(req)
+	is.NoErr(err)
+	is.Equal(res.Status(), 302)
+	cookie := res.Header("Set-Cookie")
+	is.In(cookie, "bud_session=")
+	req, err = app.GetRequest("/")
+	is.NoErr(err)
+	req.Header.Set("Cookie", strings.Split(cookie, ";")[0])
+	res, err = app.Do(req)
+	is.NoErr(err)
+	is.Equal(res.Status(), 200)
+	is.In(res.Body().String(), "alice:created")
+	// The flash is gone after it's been read
+	req, err = app.GetRequest("/")
+	is.NoErr(err)
+	req.Header.Set("Cookie", strings.Split(res.Header("Set-Cookie"), ";")[0])
+	res, err = app.Do(req)
+	is.NoErr(err)
+	is.Equal(res.Status(), 200)
+	is.In(res.Body().String(), "alice:")
+	is.True(!strings.Contains(res.Body().String(), "created"))
+	is.NoErr(app.Close())
+}
+
+func TestIntSlotConstraint(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	td := testdir.New(dir)
+	td.Files["controller/posts/controller.go"] = `
+		package posts
+		type Controller struct {}
+		func (c *Controller) Show(id int) int { return id }
+		// @route GET /posts/:slug
+		func (c *Controller) Slug(slug string) string { return slug }
+		// @route GET /posts/:uuid<uuid>/raw
+		func (c *Controller) Raw(uuid string) string { return uuid }
+	`
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(dir)
+	app, err := cli.Start(ctx, "run")
+	is.NoErr(err)
+	defer app.Close()
+	res, err := app.GetJSON("/posts/10")
+	is.NoErr(err)
+	is.Equal(res.Status(), 200)
+	is.In(res.Body().String(), `10`)
+	// Non-integer IDs fall through to the next route
+	res, err = app.GetJSON("/posts/hello-world")
+	is.NoErr(err)
+	is.Equal(res.Status(), 200)
+	is.In(res.Body().String(), `"hello-world"`)
+	res, err = app.GetJSON("/posts/0f8fad5b-d9cb-469f-a165-70867728950e/raw")
+	is.NoErr(err)
+	is.Equal(res.Status(), 200)
+	is.In(res.Body().String(), `"0f8fad5b-d9cb-469f-a165-70867728950e"`)
+	res, err = app.GetJSON("/posts/hello-world/raw")
+	is.NoErr(err)
+	is.Equal(res.Status(), 404)
+	is.NoErr(app.Close())
+}
+
+func TestStreamActions(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	td := testdir.New(dir)
+	td.Files["controller/controller.go"] = `
+		package controller
+		import "context"
+		type Controller struct {}
+		type Progress struct {
+			Percent int ` + "`json:\"percent\"`" + `
+		}
+		func (c *Controller) Progress(ctx context.Context) <-chan *Progress {
+			ch := make(chan *Progress)
+			go func() {
+				defer close(ch)
+				for i := 0; i <= 100; i += 50 {
+					select {
+					case <-ctx.Done():
+						return
+					case ch <- &Progress{i}:
+					}
+				}
+			}()
+			return ch
+		}
+		func (c *Controller) Count(max int) (func(yield func(int) bool), error) {
+			return func(yield func(int) bool) {
+				for i := 1; i <= max; i++ {
+					if !yield(i) {
+						return
+					}
+				}
+			}, nil
+		}
+	`
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(dir)
+	app, err := cli.Start(ctx, "run")
+	is.NoErr(err)
+	defer app.Close()
+	// Lines of JSON by default
+	res, err := app.GetJSON("/progress")
+	is.NoErr(err)
+	is.Equal(res.Status(), 200)
+	is.Equal(res.Header("Content-Type"), "application/x-ndjson")
+	is.Equal(res.Body().String(), "{\"percent\":0}\n{\"percent\":50}\n{\"percent\":100}\n")
+	// Server-sent events for EventSource
+	req, err := app.GetRequest("/count?max=2")
+	is.NoErr(err)
+	req.Header.Set("Accept", "text/event-stream")
+	res, err = app.Do(req)
+	is.NoErr(err)
+	is.Equal(res.Status(), 200)
+	is.Equal(res.Header("Content-Type"), "text/event-stream")
+	is.Equal(res.Body().String(), "data: 1\n\ndata: 2\n\n")
+	is.NoErr(app.Close())
+}
+
+func TestWebSocketAction(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	td := testdir.New(dir)
+	td.Files["controller/chat/controller.go"] = `
+		package chat
+		import (
+			"strings"
+			"github.com/livebud/bud/package/websocket"
+		)
+		type Room struct {
+			Conn *websocket.Conn
+		}
+		type Controller struct {
+			Room *Room
+		}
+		// Index echoes messages back to the room
+		func (c *Controller) Index(conn *websocket.Conn, room string) error {
+			if c.Room.Conn != conn {
+				return websocket.ErrClosed
+			}
+			for {
+				_, message, err := conn.Read()
+				if err != nil {
+					return err
+				}
+				reply := room + ": " + strings.ToUpper(string(message))
+				if err := conn.Write(websocket.Text, []byte(reply)); err != nil {
+					return err
+				}
+			}
+		}
+	`
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(dir)
+	app, err := cli.Start(ctx, "run")
+	is.NoErr(err)
+	defer app.Close()
+	conn, err := app.Dial(ctx, "/chat?room=general")
+	is.NoErr(err)
+	is.NoErr(conn.Write(websocket.Text, []byte("hello")))
+	_, message, err := conn.Read()
+	is.NoErr(err)
+	is.Equal(string(message), "general: HELLO")
+	is.NoErr(conn.Close())
+	// Plain requests can't be upgraded
+	res, err := app.Get("/chat")
+	is.NoErr(err)
+	is.Equal(res.Status(), 400)
+	is.NoErr(app.Close())
+}
+
+func TestWebSocketActionResults(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	td := testdir.New(dir)
+	td.Files["controller/controller.go"] = `
+		package controller
+		import "github.com/livebud/bud/package/websocket"
+		type Controller struct {}
+		func (c *Controller) Chat(conn *websocket.Conn) (string, error) {
+			return "", nil
+		}
+	`
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(dir)
+	_, err := cli.Run(ctx, "build")
+	is.True(err != nil)
+	is.In(err.Error(), `controller: websocket action "/chat" can only return an error`)
+}
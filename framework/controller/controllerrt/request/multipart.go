@@ -0,0 +1,84 @@
+package request
+
+import (
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/ajg/form"
+)
+
+var (
+	fileHeaderType  = reflect.TypeOf((*multipart.FileHeader)(nil))
+	fileHeadersType = reflect.TypeOf([]*multipart.FileHeader(nil))
+)
+
+func unmarshalMultipart(r *http.Request, v interface{}) error {
+	if r.MultipartForm == nil {
+		if err := r.ParseMultipartForm(MaxMemory); err != nil {
+			return err
+		}
+	}
+	dec := form.NewDecoder(nil)
+	dec.IgnoreCase(true)
+	dec.IgnoreUnknownKeys(true)
+	if err := dec.DecodeValues(v, r.MultipartForm.Value); err != nil {
+		return err
+	}
+	return unmarshalFiles(reflect.ValueOf(v), r.MultipartForm.File)
+}
+
+// Unmarshal the uploaded files into *multipart.FileHeader and
+// []*multipart.FileHeader fields. Fields are matched by their form or json
+// name, then case-insensitively by their field name.
+func unmarshalFiles(rv reflect.Value, files map[string][]*multipart.FileHeader) error {
+	if len(files) == 0 {
+		return nil
+	}
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		headers := findFiles(files, field)
+		if len(headers) == 0 {
+			continue
+		}
+		switch field.Type {
+		case fileHeaderType:
+			rv.Field(i).Set(reflect.ValueOf(headers[0]))
+		case fileHeadersType:
+			rv.Field(i).Set(reflect.ValueOf(headers))
+		}
+	}
+	return nil
+}
+
+func findFiles(files map[string][]*multipart.FileHeader, field reflect.StructField) []*multipart.FileHeader {
+	for _, key := range []string{"form", "json"} {
+		name := strings.Split(field.Tag.Get(key), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		if headers, ok := files[name]; ok {
+			return headers
+		}
+	}
+	for name, headers := range files {
+		if strings.EqualFold(name, field.Name) {
+			return headers
+		}
+	}
+	return nil
+}
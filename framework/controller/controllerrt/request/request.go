@@ -33,3 +33,13 @@ type Acceptable accept.AcceptSlice
 func (as Acceptable) Accepts(ctype string) bool {
 	return accept.AcceptSlice(as).Accepts(ctype)
 }
+
+// Negotiate returns the first acceptable content type in order of the client's
+// preference or an empty string if none of the types are acceptable
+func (as Acceptable) Negotiate(ctypes ...string) string {
+	ctype, err := accept.AcceptSlice(as).Negotiate(ctypes...)
+	if err != nil {
+		return ""
+	}
+	return ctype
+}
@@ -2,21 +2,44 @@ package request
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"mime"
 	"net/http"
 	"net/url"
 
 	"github.com/ajg/form"
+	"github.com/livebud/bud/package/router"
 )
 
-// Unmarshal the request data into v
+// MaxMemory is the number of bytes of a multipart form that are kept in
+// memory. The remaining file parts are stored in temporary files.
+var MaxMemory int64 = 32 << 20 // 32 MB
+
+// MaxBytes is the largest request body that's read, including uploaded files.
+// Larger bodies fail with ErrRequestTooLarge.
+var MaxBytes int64 = 64 << 20 // 64 MB
+
+// ErrUnsupportedMediaType is returned when the request body has a content type
+// that can't be unmarshaled
+var ErrUnsupportedMediaType = errors.New("request: unsupported media type")
+
+// ErrRequestTooLarge is returned when the request body is larger than MaxBytes
+var ErrRequestTooLarge = errors.New("request: body too large")
+
+// Unmarshal the request data into v. Path parameters take priority over the
+// query string, which takes priority over the body.
 func Unmarshal(r *http.Request, v interface{}) error {
 	err := unmarshalBody(r, v)
 	if err != nil {
 		return err
 	}
-	err = unmarshalURL(r.URL, v)
+	err = unmarshalValues(r.URL.Query(), v)
+	if err != nil {
+		return err
+	}
+	err = unmarshalValues(router.Params(r), v)
 	if err != nil {
 		return err
 	}
@@ -25,32 +48,79 @@ func Unmarshal(r *http.Request, v interface{}) error {
 
 func unmarshalBody(r *http.Request, v interface{}) error {
 	contentType := r.Header.Get("Content-Type")
-	if contentType == "" {
+	if contentType == "" || !hasBody(r) {
 		return nil
 	}
 	mediaType, _, err := mime.ParseMediaType(contentType)
 	if err != nil {
 		return err
 	}
+	if r.ContentLength > MaxBytes {
+		return ErrRequestTooLarge
+	}
+	body := &limitedBody{
+		ReadCloser: http.MaxBytesReader(nil, r.Body, MaxBytes),
+		limit:      MaxBytes,
+	}
+	r.Body = body
 	switch mediaType {
 	case "application/json":
-		return unmarshalJSON(r.Body, v)
+		err = unmarshalJSON(r.Body, v)
 	case "application/x-www-form-urlencoded":
-		return unmarshalForm(r, v)
+		err = unmarshalForm(r, v)
+	case "multipart/form-data":
+		err = unmarshalMultipart(r, v)
+	default:
+		return fmt.Errorf("%w %q", ErrUnsupportedMediaType, mediaType)
 	}
-	return nil
+	// The decoders don't all wrap the error from reading the body, so check
+	// whether the limit was hit ourselves
+	if body.exceeded {
+		return ErrRequestTooLarge
+	}
+	return err
+}
+
+// hasBody is true when the request was sent with a body. Chunked requests have
+// an unknown length of -1.
+func hasBody(r *http.Request) bool {
+	return r.ContentLength != 0 ||
+		len(r.TransferEncoding) > 0 ||
+		r.Header.Get("Transfer-Encoding") != ""
+}
+
+// limitedBody remembers whether reading the body went past MaxBytes
+type limitedBody struct {
+	io.ReadCloser
+	limit    int64
+	read     int64
+	exceeded bool
 }
 
-func unmarshalURL(u *url.URL, v interface{}) error {
+func (b *limitedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.read += int64(n)
+	if err != nil && err != io.EOF && b.read >= b.limit {
+		b.exceeded = true
+	}
+	return n, err
+}
+
+func unmarshalValues(values url.Values, v interface{}) error {
+	if len(values) == 0 {
+		return nil
+	}
 	dec := form.NewDecoder(nil)
 	dec.IgnoreCase(true)
 	dec.IgnoreUnknownKeys(true)
-	return dec.DecodeValues(v, u.Query())
+	return dec.DecodeValues(v, values)
 }
 
 func unmarshalForm(r *http.Request, v interface{}) error {
 	if r.PostForm == nil {
-		r.ParseForm()
+		if err := r.ParseForm(); err != nil {
+			return err
+		}
 	}
 	dec := form.NewDecoder(nil)
 	dec.IgnoreCase(true)
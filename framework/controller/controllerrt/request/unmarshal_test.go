@@ -2,11 +2,16 @@ package request_test
 
 import (
 	"bytes"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	. "github.com/livebud/bud/framework/controller/controllerrt/request"
 	"github.com/livebud/bud/internal/is"
+	"github.com/livebud/bud/package/router"
 )
 
 func TestJSONEmpty(t *testing.T) {
@@ -396,3 +401,139 @@ func TestJSONGet(t *testing.T) {
 	is.Equal("asc", s.Order)
 	is.Equal("Alice", s.Author)
 }
+
+func TestUnsupportedMediaType(t *testing.T) {
+	is := is.New(t)
+	type S struct{}
+	s := S{}
+	r := httptest.NewRequest("POST", "/", bytes.NewBufferString("hi"))
+	r.Header.Add("Content-Type", "text/plain")
+	err := Unmarshal(r, &s)
+	is.True(err != nil)
+	is.True(errors.Is(err, ErrUnsupportedMediaType))
+}
+
+func TestMultipart(t *testing.T) {
+	is := is.New(t)
+	type S struct {
+		Title  string                  `json:"title"`
+		Avatar *multipart.FileHeader   `json:"avatar"`
+		Photos []*multipart.FileHeader `form:"photos"`
+		Resume *multipart.FileHeader
+	}
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	is.NoErr(writer.WriteField("title", "hello"))
+	part, err := writer.CreateFormFile("avatar", "avatar.png")
+	is.NoErr(err)
+	part.Write([]byte("avatar"))
+	for _, name := range []string{"a.png", "b.png"} {
+		part, err := writer.CreateFormFile("photos", name)
+		is.NoErr(err)
+		part.Write([]byte(name))
+	}
+	part, err = writer.CreateFormFile("resume", "resume.pdf")
+	is.NoErr(err)
+	part.Write([]byte("resume"))
+	is.NoErr(writer.Close())
+	r := httptest.NewRequest("POST", "/", body)
+	r.Header.Add("Content-Type", writer.FormDataContentType())
+	s := S{}
+	err = Unmarshal(r, &s)
+	is.NoErr(err)
+	is.Equal(s.Title, "hello")
+	is.True(s.Avatar != nil)
+	is.Equal(s.Avatar.Filename, "avatar.png")
+	file, err := s.Avatar.Open()
+	is.NoErr(err)
+	data, err := io.ReadAll(file)
+	is.NoErr(err)
+	is.Equal(string(data), "avatar")
+	is.Equal(len(s.Photos), 2)
+	is.Equal(s.Photos[0].Filename, "a.png")
+	is.Equal(s.Photos[1].Filename, "b.png")
+	is.True(s.Resume != nil)
+	is.Equal(s.Resume.Filename, "resume.pdf")
+}
+
+func TestMultipartMaxMemory(t *testing.T) {
+	is := is.New(t)
+	maxMemory := MaxMemory
+	MaxMemory = 1
+	defer func() { MaxMemory = maxMemory }()
+	type S struct {
+		Upload *multipart.FileHeader `json:"upload"`
+	}
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("upload", "upload.txt")
+	is.NoErr(err)
+	part.Write(bytes.Repeat([]byte("a"), 1024))
+	is.NoErr(writer.Close())
+	r := httptest.NewRequest("POST", "/", body)
+	r.Header.Add("Content-Type", writer.FormDataContentType())
+	s := S{}
+	is.NoErr(Unmarshal(r, &s))
+	is.Equal(s.Upload.Size, int64(1024))
+	is.NoErr(r.MultipartForm.RemoveAll())
+}
+
+func TestPathParamsOverride(t *testing.T) {
+	is := is.New(t)
+	type S struct {
+		ID    int
+		Other string
+		Title string
+	}
+	s := S{}
+	rt := router.New()
+	rt.Post("/posts/:id", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.NoErr(Unmarshal(r, &s))
+	}))
+	r := httptest.NewRequest("POST", "/posts/10?id=20&other=o", bytes.NewBufferString(`{"id":30,"title":"t"}`))
+	r.Header.Add("Content-Type", "application/json")
+	rt.ServeHTTP(httptest.NewRecorder(), r)
+	is.Equal(10, s.ID)
+	is.Equal("o", s.Other)
+	is.Equal("t", s.Title)
+	// The query string isn't rewritten
+	is.Equal("id=20&other=o", r.URL.RawQuery)
+}
+
+func TestUnsupportedMediaTypeNoBody(t *testing.T) {
+	is := is.New(t)
+	type S struct{}
+	s := S{}
+	r := httptest.NewRequest("DELETE", "/", nil)
+	r.Header.Add("Content-Type", "text/plain")
+	err := Unmarshal(r, &s)
+	is.NoErr(err)
+}
+
+func TestRequestTooLarge(t *testing.T) {
+	is := is.New(t)
+	maxBytes := MaxBytes
+	defer func() { MaxBytes = maxBytes }()
+	MaxBytes = 10
+	type S struct {
+		Title string `json:"title"`
+	}
+	s := S{}
+	r := httptest.NewRequest("POST", "/", bytes.NewBufferString(`{"title":"hello world"}`))
+	r.Header.Add("Content-Type", "application/json")
+	err := Unmarshal(r, &s)
+	is.True(errors.Is(err, ErrRequestTooLarge))
+	// Chunked requests don't have a content length
+	r = httptest.NewRequest("POST", "/", bytes.NewBufferString(`title=hello+world`))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	r.ContentLength = -1
+	err = Unmarshal(r, &s)
+	is.True(errors.Is(err, ErrRequestTooLarge))
+	// Bodies within the limit are unmarshaled
+	r = httptest.NewRequest("POST", "/", bytes.NewBufferString(`title=hi`))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	r.ContentLength = -1
+	err = Unmarshal(r, &s)
+	is.NoErr(err)
+	is.Equal("hi", s.Title)
+}
@@ -0,0 +1,263 @@
+package request
+
+import (
+	"fmt"
+	"net/http"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ValidationError is returned when the input doesn't pass validation. Fields
+// maps the field names to their error messages.
+type ValidationError struct {
+	Fields map[string][]string `json:"fields"`
+}
+
+func (v *ValidationError) Error() string {
+	names := make([]string, 0, len(v.Fields))
+	for name := range v.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	messages := make([]string, len(names))
+	for i, name := range names {
+		messages[i] = name + " " + strings.Join(v.Fields[name], ", ")
+	}
+	return "request: invalid input. " + strings.Join(messages, ". ")
+}
+
+// StatusCode is 422 Unprocessable Entity
+func (v *ValidationError) StatusCode() int {
+	return http.StatusUnprocessableEntity
+}
+
+// Validate the input using the rules in the `validate` struct tags. Rules are
+// separated by commas:
+//
+//	Name  string `validate:"required,min=2,max=20"`
+//	Email string `validate:"required,email"`
+//	Role  string `validate:"oneof=admin member"`
+//	Slug  string `validate:"len=8,pattern=^[a-z0-9-]+$"`
+//
+// The pattern rule must come last because the pattern may contain commas.
+func Validate(in interface{}) error {
+	verr := &ValidationError{map[string][]string{}}
+	if err := validateStruct(verr, "", reflect.ValueOf(in)); err != nil {
+		return err
+	}
+	if len(verr.Fields) == 0 {
+		return nil
+	}
+	return verr
+}
+
+func validateStruct(verr *ValidationError, prefix string, rv reflect.Value) error {
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name := prefix + fieldName(field)
+		value := rv.Field(i)
+		if tag, ok := field.Tag.Lookup("validate"); ok {
+			rules, err := loadRules(tag)
+			if err != nil {
+				return fmt.Errorf("request: invalid validate tag on %s.%s. %w", rt.Name(), field.Name, err)
+			}
+			messages, err := validateValue(rules, value)
+			if err != nil {
+				return fmt.Errorf("request: unable to validate %s.%s. %w", rt.Name(), field.Name, err)
+			}
+			if len(messages) > 0 {
+				verr.Fields[name] = messages
+				continue
+			}
+		}
+		// Validate nested structs
+		if err := validateStruct(verr, name+".", value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Use the json name when it's available, since that's what clients send
+func fieldName(field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
+}
+
+type rule struct {
+	name    string
+	value   string
+	pattern *regexp.Regexp // Compiled pattern rule
+}
+
+// Parsed rules by tag, so patterns are only compiled once
+var ruleCache sync.Map
+
+func loadRules(tag string) ([]*rule, error) {
+	if rules, ok := ruleCache.Load(tag); ok {
+		return rules.([]*rule), nil
+	}
+	rules, err := parseRules(tag)
+	if err != nil {
+		return nil, err
+	}
+	ruleCache.Store(tag, rules)
+	return rules, nil
+}
+
+func parseRules(tag string) (rules []*rule, err error) {
+	for tag != "" {
+		var part string
+		if strings.HasPrefix(tag, "pattern=") {
+			part, tag = tag, ""
+		} else if i := strings.Index(tag, ","); i >= 0 {
+			part, tag = tag[:i], tag[i+1:]
+		} else {
+			part, tag = tag, ""
+		}
+		name, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		var pattern *regexp.Regexp
+		switch name {
+		case "required", "email":
+		case "min", "max", "len":
+			if _, err := strconv.ParseFloat(value, 64); err != nil {
+				return nil, fmt.Errorf("%s must be a number, got %q", name, value)
+			}
+		case "oneof":
+			if value == "" {
+				return nil, fmt.Errorf("%s must have a value", name)
+			}
+		case "pattern":
+			if value == "" {
+				return nil, fmt.Errorf("%s must have a value", name)
+			}
+			if pattern, err = regexp.Compile(value); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("unknown rule %q", name)
+		}
+		rules = append(rules, &rule{name, value, pattern})
+	}
+	return rules, nil
+}
+
+func validateValue(rules []*rule, rv reflect.Value) (messages []string, err error) {
+	// Optional values are only validated when they're present. Zero numbers are
+	// present, so they're still validated (e.g. 0 fails min=1).
+	if isAbsent(rv) {
+		for _, rule := range rules {
+			if rule.name == "required" {
+				return []string{"is required"}, nil
+			}
+		}
+		return nil, nil
+	}
+	for rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+	for _, rule := range rules {
+		message, err := validateRule(rule, rv)
+		if err != nil {
+			return nil, err
+		} else if message != "" {
+			messages = append(messages, message)
+		}
+	}
+	return messages, nil
+}
+
+// Nil pointers, empty strings and empty lists are absent
+func isAbsent(rv reflect.Value) bool {
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return rv.IsNil()
+	case reflect.Slice, reflect.Map, reflect.String:
+		return rv.Len() == 0
+	default:
+		return false
+	}
+}
+
+func validateRule(rule *rule, rv reflect.Value) (string, error) {
+	switch rule.name {
+	case "min":
+		limit, _ := strconv.ParseFloat(rule.value, 64)
+		if n, unit := measure(rv); n < limit {
+			return strings.TrimSpace("must be at least " + rule.value + " " + unit), nil
+		}
+	case "max":
+		limit, _ := strconv.ParseFloat(rule.value, 64)
+		if n, unit := measure(rv); n > limit {
+			return strings.TrimSpace("must be at most " + rule.value + " " + unit), nil
+		}
+	case "len":
+		limit, _ := strconv.ParseFloat(rule.value, 64)
+		if n, unit := measure(rv); n != limit {
+			return strings.TrimSpace("must be exactly " + rule.value + " " + unit), nil
+		}
+	case "email":
+		if rv.Kind() != reflect.String {
+			return "", fmt.Errorf("email only applies to strings, not %s", rv.Kind())
+		}
+		if addr, err := mail.ParseAddress(rv.String()); err != nil || addr.Address != rv.String() {
+			return "must be a valid email address", nil
+		}
+	case "pattern":
+		if rv.Kind() != reflect.String {
+			return "", fmt.Errorf("pattern only applies to strings, not %s", rv.Kind())
+		}
+		if !rule.pattern.MatchString(rv.String()) {
+			return "must match " + rule.value, nil
+		}
+	case "oneof":
+		options := strings.Fields(rule.value)
+		actual := fmt.Sprint(rv.Interface())
+		for _, option := range options {
+			if actual == option {
+				return "", nil
+			}
+		}
+		return "must be one of " + strings.Join(options, ", "), nil
+	}
+	return "", nil
+}
+
+// Measure returns the number or the length of the value along with its unit
+func measure(rv reflect.Value) (n float64, unit string) {
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), ""
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), ""
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), ""
+	case reflect.String:
+		return float64(len([]rune(rv.String()))), "characters"
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return float64(rv.Len()), "items"
+	default:
+		return 0, ""
+	}
+}
@@ -0,0 +1,109 @@
+package request_test
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/livebud/bud/framework/controller/controllerrt/request"
+	"github.com/livebud/bud/internal/is"
+)
+
+func TestValidateValid(t *testing.T) {
+	is := is.New(t)
+	type S struct {
+		Name  string   `json:"name" validate:"required,min=2,max=5"`
+		Email string   `json:"email" validate:"email"`
+		Age   int      `json:"age" validate:"min=18"`
+		Role  string   `json:"role" validate:"oneof=admin member"`
+		Slug  string   `json:"slug" validate:"len=4,pattern=^[a-z,]+$"`
+		Tags  []string `json:"tags" validate:"max=2"`
+	}
+	s := S{"Ann", "ann@example.com", 21, "admin", "a,bc", []string{"a"}}
+	is.NoErr(Validate(&s))
+}
+
+func TestValidateOptional(t *testing.T) {
+	is := is.New(t)
+	type S struct {
+		Email string  `validate:"email"`
+		Age   *int    `validate:"min=18"`
+		Role  *string `validate:"oneof=admin member"`
+	}
+	is.NoErr(Validate(&S{}))
+}
+
+func TestValidateInvalid(t *testing.T) {
+	is := is.New(t)
+	type S struct {
+		Name  string   `json:"name" validate:"required"`
+		Title string   `json:"title,omitempty" validate:"min=2,max=3"`
+		Email string   `json:"email" validate:"email"`
+		Age   int      `validate:"min=18"`
+		Role  string   `json:"role" validate:"oneof=admin member"`
+		Slug  string   `json:"slug" validate:"len=4,pattern=^[a-z]+$"`
+		Tags  []string `json:"tags" validate:"max=1"`
+	}
+	s := S{"", "abcd", "nope", 3, "owner", "AB", []string{"a", "b"}}
+	err := Validate(&s)
+	is.True(err != nil)
+	var verr *ValidationError
+	is.True(errors.As(err, &verr))
+	is.Equal(len(verr.Fields), 7)
+	is.Equal(verr.Fields["name"], []string{"is required"})
+	is.Equal(verr.Fields["title"], []string{"must be at most 3 characters"})
+	is.Equal(verr.Fields["email"], []string{"must be a valid email address"})
+	is.Equal(verr.Fields["Age"], []string{"must be at least 18"})
+	is.Equal(verr.Fields["role"], []string{"must be one of admin, member"})
+	is.Equal(verr.Fields["slug"], []string{"must be exactly 4 characters", "must match ^[a-z]+$"})
+	is.Equal(verr.Fields["tags"], []string{"must be at most 1 items"})
+}
+
+func TestValidateNested(t *testing.T) {
+	is := is.New(t)
+	type Address struct {
+		City string `json:"city" validate:"required"`
+	}
+	type S struct {
+		Address *Address `json:"address"`
+	}
+	is.NoErr(Validate(&S{}))
+	err := Validate(&S{&Address{}})
+	var verr *ValidationError
+	is.True(errors.As(err, &verr))
+	is.Equal(verr.Fields["address.city"], []string{"is required"})
+}
+
+func TestValidateInvalidTag(t *testing.T) {
+	is := is.New(t)
+	type S struct {
+		Name string `validate:"requird"`
+	}
+	err := Validate(&S{"a"})
+	is.True(err != nil)
+	is.Equal(err.Error(), `request: invalid validate tag on S.Name. unknown rule "requird"`)
+}
+
+func TestValidateZero(t *testing.T) {
+	is := is.New(t)
+	type S struct {
+		Count int     `json:"count" validate:"min=1"`
+		Level int     `json:"level" validate:"oneof=1 2 3"`
+		Price float64 `json:"price" validate:"min=0.5"`
+	}
+	err := Validate(&S{})
+	var verr *ValidationError
+	is.True(errors.As(err, &verr))
+	is.Equal(verr.Fields["count"], []string{"must be at least 1"})
+	is.Equal(verr.Fields["level"], []string{"must be one of 1, 2, 3"})
+	is.Equal(verr.Fields["price"], []string{"must be at least 0.5"})
+}
+
+func TestValidateInvalidPattern(t *testing.T) {
+	is := is.New(t)
+	type S struct {
+		Slug string `validate:"pattern=^[a-z+$"`
+	}
+	err := Validate(&S{"a"})
+	is.True(err != nil)
+	is.In(err.Error(), `request: invalid validate tag on S.Slug. error parsing regexp`)
+}
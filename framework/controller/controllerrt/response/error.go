@@ -0,0 +1,83 @@
+package response
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/livebud/bud/package/session"
+)
+
+// Sentinel errors that actions can return or wrap to pick the status code of
+// the response (e.g. fmt.Errorf("post %d: %w", id, response.ErrNotFound))
+var (
+	ErrNotFound      error = &statusError{http.StatusNotFound}
+	ErrUnauthorized  error = &statusError{http.StatusUnauthorized}
+	ErrForbidden     error = &statusError{http.StatusForbidden}
+	ErrConflict      error = &statusError{http.StatusConflict}
+	ErrUnprocessable error = &statusError{http.StatusUnprocessableEntity}
+)
+
+type statusError struct {
+	status int
+}
+
+func (e *statusError) Error() string {
+	return strings.ToLower(http.StatusText(e.status))
+}
+
+func (e *statusError) StatusCode() int {
+	return e.status
+}
+
+// StatusCoder is implemented by errors that choose their own status code
+type StatusCoder interface {
+	StatusCode() int
+}
+
+// StatusCode returns the status code of the error. Errors that don't
+// implement StatusCoder are 500 Internal Server Errors.
+func StatusCode(err error) int {
+	var coder StatusCoder
+	if errors.As(err, &coder) {
+		if status := coder.StatusCode(); status >= 400 && status <= 599 {
+			return status
+		}
+	}
+	return http.StatusInternalServerError
+}
+
+// ErrorPage renders an HTML error page
+type ErrorPage func(status int, err error) http.Handler
+
+// Error responds to an error returned by an action. JSON requests receive the
+// error with its status code. HTML requests render the error page, if there is
+// one. Non-GET requests that failed with a 500 are redirected back instead,
+// flashing the error to the session when there is one.
+func Error(r *http.Request, err error, page ErrorPage) http.Handler {
+	status := StatusCode(err)
+	return &Format{
+		HTML: errorHTML(r, status, err, page),
+		JSON: Status(status).JSON(map[string]string{"error": err.Error()}),
+	}
+}
+
+func errorHTML(r *http.Request, status int, err error, page ErrorPage) http.Handler {
+	switch {
+	case r.Method != http.MethodGet && status == http.StatusInternalServerError:
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Flash the error so the page we redirect back to can show it. This
+			// only happens once we know the response is HTML.
+			if sess, err2 := session.From(r.Context()); err2 == nil {
+				sess.Flash("error", err.Error())
+			}
+			Status(http.StatusSeeOther).RedirectBack(r.URL.Path).ServeHTTP(w, r)
+		})
+	case page != nil:
+		return page(status, err)
+	default:
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, err.Error(), status)
+		})
+	}
+}
@@ -1,7 +1,9 @@
 package response
 
 import (
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"path"
@@ -189,3 +191,62 @@ func RedirectPath(r *http.Request, subpath string) string {
 		return r.URL.Path
 	}
 }
+
+// errorsCookie holds the validation errors between the redirect and the next
+// render
+const errorsCookie = "bud_errors"
+
+// Invalid responds to input that couldn't be validated. JSON requests receive
+// a 422 with the errors for each field. HTML requests are redirected back
+// with the errors kept for the next render. Other errors, like a malformed
+// validate tag, are a bug in the app, so they're responded to as a 500.
+func Invalid(err error, page ErrorPage) http.Handler {
+	var verr *request.ValidationError
+	if !errors.As(err, &verr) {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Error(r, err, page).ServeHTTP(w, r)
+		})
+	}
+	return &Format{
+		HTML: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if value, err := json.Marshal(verr.Fields); err == nil {
+				http.SetCookie(w, &http.Cookie{
+					Name:     errorsCookie,
+					Value:    base64.URLEncoding.EncodeToString(value),
+					Path:     "/",
+					HttpOnly: true,
+					SameSite: http.SameSiteLaxMode,
+				})
+			}
+			Status(http.StatusSeeOther).RedirectBack(r.URL.Path).ServeHTTP(w, r)
+		}),
+		JSON: Status(http.StatusUnprocessableEntity).JSON(map[string]interface{}{
+			"error":  err.Error(),
+			"fields": verr.Fields,
+		}),
+	}
+}
+
+// Errors adds the validation errors from the previous request to the props
+// under the "errors" key, then clears them so they're only rendered once.
+func Errors(w http.ResponseWriter, r *http.Request, props map[string]interface{}) map[string]interface{} {
+	cookie, err := r.Cookie(errorsCookie)
+	if err != nil {
+		return props
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:   errorsCookie,
+		Path:   "/",
+		MaxAge: -1,
+	})
+	value, err := base64.URLEncoding.DecodeString(cookie.Value)
+	if err != nil {
+		return props
+	}
+	fields := map[string][]string{}
+	if err := json.Unmarshal(value, &fields); err != nil {
+		return props
+	}
+	props["errors"] = fields
+	return props
+}
@@ -0,0 +1,73 @@
+package response_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/livebud/bud/framework/controller/controllerrt/request"
+	"github.com/livebud/bud/framework/controller/controllerrt/response"
+	"github.com/livebud/bud/internal/is"
+	"github.com/livebud/bud/package/session"
+)
+
+func TestInvalid(t *testing.T) {
+	is := is.New(t)
+	verr := &request.ValidationError{Fields: map[string][]string{"title": {"is required"}}}
+	// Validation errors are a 422 for JSON requests
+	req := httptest.NewRequest(http.MethodPost, "/posts", nil)
+	req.Header.Set("Accept", "application/json")
+	rec := httptest.NewRecorder()
+	response.Invalid(verr, nil).ServeHTTP(rec, req)
+	is.Equal(rec.Code, http.StatusUnprocessableEntity)
+	is.In(rec.Body.String(), `"fields":{"title":["is required"]}`)
+	// And redirect HTML requests back
+	req = httptest.NewRequest(http.MethodPost, "/posts", nil)
+	req.Header.Set("Accept", "text/html")
+	rec = httptest.NewRecorder()
+	response.Invalid(verr, nil).ServeHTTP(rec, req)
+	is.Equal(rec.Code, http.StatusSeeOther)
+	// Other errors are bugs, so they're a 500 for JSON and HTML requests
+	err := errors.New(`validate: unknown rule "requred" on field "Title"`)
+	req = httptest.NewRequest(http.MethodGet, "/posts", nil)
+	req.Header.Set("Accept", "application/json")
+	rec = httptest.NewRecorder()
+	response.Invalid(err, nil).ServeHTTP(rec, req)
+	is.Equal(rec.Code, http.StatusInternalServerError)
+	is.In(rec.Body.String(), `"error":"validate: unknown rule`)
+	req = httptest.NewRequest(http.MethodGet, "/posts", nil)
+	req.Header.Set("Accept", "text/html")
+	rec = httptest.NewRecorder()
+	response.Invalid(err, nil).ServeHTTP(rec, req)
+	is.Equal(rec.Code, http.StatusInternalServerError)
+	is.True(strings.HasPrefix(rec.Body.String(), "validate: unknown rule"))
+}
+
+func TestErrorFlash(t *testing.T) {
+	is := is.New(t)
+	store, err := session.NewCookieStore([]byte("01234567890123456789012345678901"))
+	is.NoErr(err)
+	var flashes []string
+	handler := session.Middleware(store).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response.Error(r, errors.New("unable to save"), nil).ServeHTTP(w, r)
+		sess, err := session.From(r.Context())
+		is.NoErr(err)
+		flashes = sess.Flashes("error")
+	}))
+	// JSON requests don't leave a flash for the next HTML page
+	req := httptest.NewRequest(http.MethodPost, "/posts", nil)
+	req.Header.Set("Accept", "application/json")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	is.Equal(rec.Code, http.StatusInternalServerError)
+	is.Equal(len(flashes), 0)
+	// HTML requests are redirected back with the error flashed
+	req = httptest.NewRequest(http.MethodPost, "/posts", nil)
+	req.Header.Set("Accept", "text/html")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	is.Equal(rec.Code, http.StatusSeeOther)
+	is.Equal(flashes, []string{"unable to save"})
+}
@@ -0,0 +1,120 @@
+package response
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+
+	"github.com/livebud/bud/framework/controller/controllerrt/request"
+	"github.com/livebud/bud/package/hot"
+)
+
+// Stream responds with each value from a receive-only channel or an iterator
+// (e.g. func(yield func(*Post) bool)) as soon as it's produced. Clients that
+// accept text/event-stream get server-sent events, otherwise each value is
+// written as a line of JSON. Values that are *hot.Event are written as-is.
+//
+// Streaming stops when the channel is closed, the iterator returns or the
+// client disconnects. The request's context is cancelled when the client
+// disconnects, so actions should pass it along to whatever produces the
+// values.
+func Stream(source interface{}) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "response: response writer is not a flusher", http.StatusInternalServerError)
+			return
+		}
+		encode := encodeLine
+		header := w.Header()
+		switch request.Accepts(r).Negotiate("application/x-ndjson", "text/event-stream") {
+		case "text/event-stream":
+			encode = encodeEvent
+			header.Set("Content-Type", "text/event-stream")
+		default:
+			header.Set("Content-Type", "application/x-ndjson")
+		}
+		header.Set("Cache-Control", "no-cache")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+		ctx := r.Context()
+		done := false
+		send := func(value interface{}) bool {
+			if done || ctx.Err() != nil {
+				done = true
+				return false
+			}
+			data, err := encode(value)
+			if err != nil {
+				// The status has already been sent, so write the error out as the
+				// last value in the stream
+				data, _ = encode(errorEvent(err))
+				done = true
+			}
+			if _, err := w.Write(data); err != nil {
+				done = true
+				return false
+			}
+			flusher.Flush()
+			return !done
+		}
+		each(ctx, reflect.ValueOf(source), send)
+	})
+}
+
+// Send each value of the channel or iterator until send returns false
+func each(ctx context.Context, source reflect.Value, send func(value interface{}) bool) {
+	switch source.Kind() {
+	case reflect.Chan:
+		cases := []reflect.SelectCase{
+			{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())},
+			{Dir: reflect.SelectRecv, Chan: source},
+		}
+		for {
+			chosen, value, ok := reflect.Select(cases)
+			if chosen == 0 || !ok || !send(value.Interface()) {
+				return
+			}
+		}
+	case reflect.Func:
+		if source.IsNil() {
+			return
+		}
+		yield := reflect.MakeFunc(source.Type().In(0), func(args []reflect.Value) []reflect.Value {
+			return []reflect.Value{reflect.ValueOf(send(args[0].Interface()))}
+		})
+		source.Call([]reflect.Value{yield})
+	}
+}
+
+// Error event that ends the stream
+func errorEvent(err error) *hot.Event {
+	data, _ := json.Marshal(map[string]string{"error": err.Error()})
+	return &hot.Event{Type: "error", Data: data}
+}
+
+// Encode the value as a server-sent event
+func encodeEvent(value interface{}) ([]byte, error) {
+	if event, ok := value.(*hot.Event); ok {
+		return event.Format().Bytes(), nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	event := &hot.Event{Data: data}
+	return event.Format().Bytes(), nil
+}
+
+// Encode the value as a line of JSON
+func encodeLine(value interface{}) ([]byte, error) {
+	if event, ok := value.(*hot.Event); ok {
+		return append(append([]byte{}, event.Data...), '\n'), nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
@@ -0,0 +1,104 @@
+package response_test
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/livebud/bud/framework/controller/controllerrt/response"
+	"github.com/livebud/bud/internal/is"
+	"github.com/livebud/bud/package/hot"
+)
+
+type post struct {
+	ID int `json:"id"`
+}
+
+func TestStreamChannel(t *testing.T) {
+	is := is.New(t)
+	ch := make(chan *post, 2)
+	ch <- &post{1}
+	ch <- &post{2}
+	close(ch)
+	r := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	response.Stream((<-chan *post)(ch)).ServeHTTP(w, r)
+	is.Equal(w.Code, 200)
+	is.Equal(w.Header().Get("Content-Type"), "application/x-ndjson")
+	is.Equal(w.Body.String(), "{\"id\":1}\n{\"id\":2}\n")
+}
+
+func TestStreamIterator(t *testing.T) {
+	is := is.New(t)
+	iterator := func(yield func(*post) bool) {
+		for i := 1; i <= 2; i++ {
+			if !yield(&post{i}) {
+				return
+			}
+		}
+	}
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Accept", "text/event-stream")
+	w := httptest.NewRecorder()
+	response.Stream(iterator).ServeHTTP(w, r)
+	is.Equal(w.Code, 200)
+	is.Equal(w.Header().Get("Content-Type"), "text/event-stream")
+	is.Equal(w.Body.String(), "data: {\"id\":1}\n\ndata: {\"id\":2}\n\n")
+}
+
+func TestStreamEvents(t *testing.T) {
+	is := is.New(t)
+	iterator := func(yield func(*hot.Event) bool) {
+		yield(&hot.Event{ID: "1", Type: "progress", Data: []byte(`50`)})
+	}
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Accept", "text/event-stream")
+	w := httptest.NewRecorder()
+	response.Stream(iterator).ServeHTTP(w, r)
+	is.Equal(w.Body.String(), "id: 1\nevent: progress\ndata: 50\n\n")
+}
+
+func TestStreamDisconnect(t *testing.T) {
+	is := is.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	yielded := 0
+	iterator := func(yield func(int) bool) {
+		for i := 0; i < 10; i++ {
+			if i == 2 {
+				cancel()
+			}
+			if !yield(i) {
+				return
+			}
+			yielded++
+		}
+	}
+	r := httptest.NewRequest("GET", "/", nil).WithContext(ctx)
+	w := httptest.NewRecorder()
+	response.Stream(iterator).ServeHTTP(w, r)
+	is.Equal(yielded, 2)
+	is.Equal(w.Body.String(), "0\n1\n")
+	// Channels stop when the client disconnects, even if they're not closed
+	ch := make(chan int)
+	w = httptest.NewRecorder()
+	response.Stream((<-chan int)(ch)).ServeHTTP(w, r)
+	is.Equal(w.Body.String(), "")
+}
+
+func TestStreamEncodeError(t *testing.T) {
+	is := is.New(t)
+	iterator := func(yield func(interface{}) bool) {
+		if !yield(1) {
+			return
+		}
+		if !yield(func() {}) {
+			return
+		}
+		yield(errors.New("unreachable"))
+	}
+	r := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	response.Stream(iterator).ServeHTTP(w, r)
+	is.Equal(w.Body.String(), "1\n{\"error\":\"json: unsupported type: func()\"}\n")
+}
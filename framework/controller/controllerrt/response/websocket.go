@@ -0,0 +1,24 @@
+package response
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/livebud/bud/package/websocket"
+)
+
+// WebSocket closes the upgraded connection once the action returns. Errors
+// close the connection with an internal error status. The connection has been
+// hijacked, so there's nothing left to write to the response.
+func WebSocket(conn *websocket.Conn, err error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var closeErr *websocket.CloseError
+		switch {
+		case err == nil, errors.Is(err, io.EOF), errors.Is(err, websocket.ErrClosed), errors.As(err, &closeErr):
+			conn.Close()
+		default:
+			conn.CloseWith(websocket.StatusInternalError, err.Error())
+		}
+	})
+}
@@ -9,6 +9,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/livebud/bud/internal/entrypoint"
 	"github.com/livebud/bud/internal/gois"
 	"github.com/livebud/bud/internal/valid"
 
@@ -17,6 +18,9 @@ import (
 	"github.com/livebud/bud/package/di"
 	"github.com/livebud/bud/package/gomod"
 	"github.com/livebud/bud/package/parser"
+	"github.com/livebud/bud/package/router"
+	"github.com/livebud/bud/package/router/lex"
+	"github.com/livebud/bud/package/router/radix"
 	"github.com/matthewmueller/gotext"
 	"github.com/matthewmueller/text"
 )
@@ -34,6 +38,7 @@ func Load(fsys fs.FS, injector *di.Injector, module *gomod.Module, parser *parse
 		injector:  injector,
 		module:    module,
 		parser:    parser,
+		routes:    map[string]string{},
 	}
 	return loader.Load()
 }
@@ -41,21 +46,25 @@ func Load(fsys fs.FS, injector *di.Injector, module *gomod.Module, parser *parse
 // loader struct
 type loader struct {
 	bail.Struct
-	fsys      fs.FS
-	injector  *di.Injector
-	imports   *imports.Set
-	providers *providerSet
-	module    *gomod.Module
-	parser    *parser.Parser
+	fsys       fs.FS
+	injector   *di.Injector
+	imports    *imports.Set
+	providers  *providerSet
+	module     *gomod.Module
+	parser     *parser.Parser
+	routes     map[string]string // "METHOD route key" => action key
+	errorPages bool              // True if there are error pages in view/
 }
 
 // load fn
 func (l *loader) Load() (state *State, err error) {
 	defer l.Recover2(&err, "controller: unable to load state")
 	state = new(State)
+	l.errorPages = l.hasErrorPages()
 	state.Controller = l.loadController("controller")
 	state.Providers = l.providers.List()
 	l.imports.AddNamed("router", "github.com/livebud/bud/package/router")
+	l.imports.AddNamed("middleware", "github.com/livebud/bud/package/middleware")
 	state.Imports = l.imports.List()
 	return state, nil
 }
@@ -100,6 +109,7 @@ func (l *loader) loadController(controllerPath string) *Controller {
 		return controller
 	}
 	controller.Actions = l.loadActions(controller, stct)
+	controller.Middleware = l.loadMiddleware(controller, stct)
 	return controller
 }
 
@@ -133,13 +143,12 @@ func (l *loader) loadControllerRoute(controllerPath string) string {
 }
 
 func (l *loader) loadActions(controller *Controller, stct *parser.Struct) (actions []*Action) {
-	var usesResponse bool
 	for _, method := range stct.PublicMethods() {
-		action := l.loadAction(controller, method)
-		if !action.HandlerFunc {
-			usesResponse = true
+		// The Middleware method wraps the actions, it's not an action itself
+		if method.Name() == "Middleware" && l.isMiddleware(method.Params(), method.Results()) {
+			continue
 		}
-		actions = append(actions, action)
+		actions = append(actions, l.loadAction(controller, method))
 	}
 	// Add the imports if we have more than one action
 	if len(actions) > 0 {
@@ -149,9 +158,8 @@ func (l *loader) loadActions(controller *Controller, stct *parser.Struct) (actio
 		}
 		l.imports.Add(importPath)
 		l.imports.Add("net/http")
-		if usesResponse {
-			l.imports.Add("github.com/livebud/bud/framework/controller/controllerrt/response")
-		}
+		// Errors are handled by the response package, even for handler funcs
+		l.imports.Add("github.com/livebud/bud/framework/controller/controllerrt/response")
 	}
 	return actions
 }
@@ -165,17 +173,39 @@ func (l *loader) loadAction(controller *Controller, method *parser.Function) *Ac
 	action.Route = l.loadActionRoute(controller.Route, action.Name)
 	action.Key = l.loadActionKey(controller.Path, action.Name)
 	action.View = l.loadView(controller.Path, action.Key, action.Route)
+	action.ErrorPages = action.View == nil && l.errorPages
+	if action.ErrorPages {
+		l.imports.Add(l.module.Import("bud/internal/web/view"))
+	}
 	action.Method = l.loadActionMethod(action.Name)
+	// Override the RESTful method and route with the @route annotation
+	if annotation, ok := findRouteAnnotation(method.Doc()); ok {
+		action.Method, action.Route = l.loadRouteAnnotation(controller.Route, action.Key, annotation)
+	}
 	params := method.Params()
 	results := method.Results()
 	action.HandlerFunc = l.isHandlerFunc(params, results)
 	if !action.HandlerFunc {
 		action.Params = l.loadActionParams(params)
+		action.Route = l.constrainRoute(action.Route, action.Params)
 		action.Input = l.loadActionInput(action.Params)
-		action.Results = l.loadActionResults(results)
+		action.WebSocket = hasWebSocketParam(action.Params)
+		if _, ok := StreamValue(results); ok {
+			action.Stream = true
+			action.Results = l.loadStreamResults(results)
+		} else {
+			action.Results = l.loadActionResults(results)
+		}
+	}
+	l.checkDuplicateRoute(action)
+	if action.WebSocket {
+		l.checkWebSocket(action)
+		l.imports.Add("github.com/livebud/bud/package/websocket")
+	} else if !action.Stream {
+		action.RespondJSON = len(action.Results) > 0
+		action.RespondHTML = l.loadRespondHTML(action.Results)
+		action.PropsKey = action.Results.propsKey()
 	}
-	action.RespondJSON = len(action.Results) > 0
-	action.RespondHTML = l.loadRespondHTML(action.Results)
 	action.Provider = l.loadProvider(controller, method)
 	action.Redirect = l.loadActionRedirect(action)
 	return action
@@ -213,6 +243,94 @@ func (l *loader) isHandlerFunc(params []*parser.Param, results []*parser.Result)
 	return true
 }
 
+// Load the middleware that wraps the controller's actions and the actions of
+// its nested controllers. Either the controller implements
+// middleware.Middleware itself or it has fields that do.
+func (l *loader) loadMiddleware(controller *Controller, stct *parser.Struct) *Middleware {
+	middleware := new(Middleware)
+	if method := stct.Method("Middleware"); method != nil && l.isMiddleware(method.Params(), method.Results()) {
+		middleware.Stack = []string{"controller"}
+		middleware.Provider = l.loadProvider(controller, method)
+	} else {
+		for _, field := range stct.PublicFields() {
+			if l.isMiddlewareField(field) {
+				middleware.Stack = append(middleware.Stack, "controller."+field.Name())
+			}
+		}
+		if len(middleware.Stack) == 0 {
+			return nil
+		}
+		middleware.Provider = l.loadMiddlewareProvider(controller, stct)
+	}
+	l.imports.Add("net/http")
+	l.imports.Add("github.com/livebud/bud/framework/controller/controllerrt/response")
+	return middleware
+}
+
+// Reuse the actions' provider when possible, otherwise load the controller
+// struct by its pointer
+func (l *loader) loadMiddlewareProvider(controller *Controller, stct *parser.Struct) *di.Provider {
+	for _, method := range stct.Methods() {
+		if provider := l.loadProvider(controller, method); provider != nil {
+			return provider
+		}
+	}
+	importPath, err := stct.File().Import()
+	if err != nil {
+		l.Bail(err)
+	}
+	return l.wireProvider(controller, importPath, stct.Name(), "*"+stct.Name())
+}
+
+// Check that the field's type implements middleware.Middleware
+func (l *loader) isMiddlewareField(field *parser.Field) bool {
+	dt := field.Type()
+	if star, ok := dt.(*parser.StarType); ok {
+		dt = star.Inner()
+	}
+	switch dt.(type) {
+	case *parser.IdentType, *parser.SelectorType:
+	default:
+		return false
+	}
+	def, err := parser.Definition(dt)
+	if err != nil {
+		l.Bail(fmt.Errorf("controller: unable to find definition for field %s. %w", field.Name(), err))
+	}
+	if def.Kind() == parser.KindBuiltin {
+		return false
+	}
+	pkg := def.Package()
+	if iface := pkg.Interface(def.Name()); iface != nil {
+		method := iface.Method("Middleware")
+		return method != nil && l.isMiddleware(method.Params(), method.Results())
+	}
+	for _, fn := range pkg.Functions() {
+		recv := fn.Receiver()
+		if recv == nil || fn.Name() != "Middleware" || parser.TypeName(recv.Type()) != def.Name() {
+			continue
+		}
+		return l.isMiddleware(fn.Params(), fn.Results())
+	}
+	return false
+}
+
+// Middleware has the signature: func(next http.Handler) http.Handler
+func (l *loader) isMiddleware(params []*parser.Param, results []*parser.Result) bool {
+	if len(params) != 1 || len(results) != 1 {
+		return false
+	}
+	isParam, err := parser.IsImportType(params[0].Type(), "net/http", "Handler")
+	if err != nil {
+		l.Bail(err)
+	}
+	isResult, err := parser.IsImportType(results[0].Type(), "net/http", "Handler")
+	if err != nil {
+		l.Bail(err)
+	}
+	return isParam && isResult
+}
+
 // Route to the action
 func (l *loader) loadActionRoute(controllerRoute, actionName string) string {
 	switch actionName {
@@ -232,10 +350,137 @@ func (l *loader) loadActionRoute(controllerRoute, actionName string) string {
 const (
 	methodGet    = "Get"
 	methodPost   = "Post"
+	methodPut    = "Put"
 	methodPatch  = "Patch"
 	methodDelete = "Delete"
 )
 
+// routeAnnotation overrides the default RESTful method and route of an action
+// (e.g. "// @route POST /sessions/destroy")
+const routeAnnotation = "@route"
+
+// findRouteAnnotation finds the route annotation within the doc comment
+func findRouteAnnotation(doc string) (annotation string, ok bool) {
+	for _, line := range strings.Split(doc, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || fields[0] != routeAnnotation {
+			continue
+		}
+		return strings.Join(fields[1:], " "), true
+	}
+	return "", false
+}
+
+// Load the method and route from the annotation. Routes without a leading
+// slash are relative to the controller's route.
+func (l *loader) loadRouteAnnotation(controllerRoute, actionKey, annotation string) (method, route string) {
+	fields := strings.Fields(annotation)
+	if len(fields) != 2 {
+		l.Bail(fmt.Errorf("controller: invalid %s %q for %q, expected a method and a route (e.g. %s POST /users)", routeAnnotation, annotation, actionKey, routeAnnotation))
+	}
+	switch strings.ToUpper(fields[0]) {
+	case "GET":
+		method = methodGet
+	case "POST":
+		method = methodPost
+	case "PUT":
+		method = methodPut
+	case "PATCH":
+		method = methodPatch
+	case "DELETE":
+		method = methodDelete
+	default:
+		l.Bail(fmt.Errorf("controller: invalid %s method %q for %q", routeAnnotation, fields[0], actionKey))
+	}
+	route = path.Clean(fields[1])
+	if !strings.HasPrefix(route, "/") {
+		route = path.Join(controllerRoute, route)
+	}
+	return method, route
+}
+
+// Constrain the route's slots to integers when the action's parameter for
+// that slot is an integer (e.g. /posts/:id => /posts/:id<int>). Slots that
+// already have a constraint are left alone.
+func (l *loader) constrainRoute(route string, params []*ActionParam) string {
+	lexer := lex.New(route)
+	out := new(strings.Builder)
+	for {
+		token := lexer.Next()
+		switch token.Type {
+		case lex.EndToken:
+			return out.String()
+		case lex.ErrorToken:
+			// Leave invalid routes for the router to report
+			return route
+		case lex.SlotToken, lex.QuestionToken:
+			if token.Constraint() != "" || !hasIntParam(params, token.Slot()) {
+				out.WriteString(token.Value)
+				continue
+			}
+			out.WriteString(":" + token.Slot() + "<int>")
+			if token.Type == lex.QuestionToken {
+				out.WriteString("?")
+			}
+		default:
+			out.WriteString(token.Value)
+		}
+	}
+}
+
+func hasIntParam(params []*ActionParam, slot string) bool {
+	for _, param := range params {
+		if param.Snake != slot && param.Name != slot {
+			continue
+		}
+		switch param.Type {
+		case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64":
+			return true
+		}
+	}
+	return false
+}
+
+// Ensure that two actions don't map to the same method and route. Routes are
+// compared the way the router matches them, so slot names don't matter (e.g.
+// /posts/:id and /posts/:post_id) and optional slots match either way.
+func (l *loader) checkDuplicateRoute(action *Action) {
+	routes, err := radix.Expand(router.Normalize(action.Route))
+	if err != nil {
+		// Leave invalid routes for the router to report
+		return
+	}
+	method := strings.ToUpper(action.Method)
+	for _, route := range routes {
+		if existing, ok := l.routes[method+" "+routeKey(route)]; ok {
+			l.Bail(fmt.Errorf("controller: %q and %q both map to %q", existing, action.Key, method+" "+action.Route))
+		}
+	}
+	for _, route := range routes {
+		l.routes[method+" "+routeKey(route)] = action.Key
+	}
+}
+
+// Key the route by its slot constraints instead of its slot names
+// (e.g. /posts/:id<int> => /posts/:<int>)
+func routeKey(route string) string {
+	lexer := lex.New(route)
+	out := new(strings.Builder)
+	for {
+		token := lexer.Next()
+		switch token.Type {
+		case lex.EndToken, lex.ErrorToken:
+			return out.String()
+		case lex.SlotToken:
+			out.WriteString(":<" + token.Constraint() + ">")
+		case lex.StarToken:
+			out.WriteString(":*")
+		default:
+			out.WriteString(token.Value)
+		}
+	}
+}
+
 // Method is the HTTP method for this controller
 func (l *loader) loadActionMethod(actionName string) string {
 	switch actionName {
@@ -273,17 +518,31 @@ func (l *loader) loadView(controllerKey, actionKey, actionRoute string) *View {
 		l.imports.Add(l.module.Import("bud/internal/web/view"))
 		return &View{
 			Route: actionRoute,
+			Path:  path.Join(viewDir, name),
 		}
 	}
 	return nil
 }
 
+// Actions without a view still render errors with the error pages
+func (l *loader) hasErrorPages() bool {
+	errorViews, err := entrypoint.ListErrors(l.fsys, "view")
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return false
+		}
+		l.Bail(fmt.Errorf("controller: unable to list the error pages. %w", err))
+	}
+	return len(errorViews) > 0
+}
+
 func (l *loader) loadActionParams(params []*parser.Param) (inputs []*ActionParam) {
 	numParams := len(params)
 	for nth, param := range params {
 		inputs = append(inputs, l.loadActionParam(param, nth, numParams))
 	}
 	if len(inputs) > 0 {
+		l.imports.AddStd("errors")
 		l.imports.Add("github.com/livebud/bud/framework/controller/controllerrt/request")
 	}
 	return inputs
@@ -302,6 +561,9 @@ func (l *loader) loadActionParam(param *parser.Param, nth, numParams int) *Actio
 	ap.Tag = fmt.Sprintf("`json:\"%[1]s\"`", tagValue(ap.Snake))
 	ap.Kind = string(dec.Kind())
 	switch {
+	// Handle *websocket.Conn, which is upgraded from the request
+	case l.isWebSocketConn(param.Type()):
+		ap.Variable = websocketConn
 	// Single struct input
 	case numParams == 1 && dec.Kind() == parser.KindStruct:
 		ap.Variable = "in"
@@ -314,6 +576,42 @@ func (l *loader) loadActionParam(param *parser.Param, nth, numParams int) *Actio
 	return ap
 }
 
+// Variable that holds the upgraded WebSocket connection
+const websocketConn = "websocketConn"
+
+// Check if the param is a *websocket.Conn
+func (l *loader) isWebSocketConn(dt parser.Type) bool {
+	star, ok := dt.(*parser.StarType)
+	if !ok {
+		return false
+	}
+	isConn, err := parser.IsImportType(star.Inner(), "github.com/livebud/bud/package/websocket", "Conn")
+	if err != nil {
+		l.Bail(err)
+	}
+	return isConn
+}
+
+func hasWebSocketParam(params []*ActionParam) bool {
+	for _, param := range params {
+		if param.IsWebSocket() {
+			return true
+		}
+	}
+	return false
+}
+
+// WebSocket handshakes are GET requests. The action takes over the
+// connection, so it can only return an error.
+func (l *loader) checkWebSocket(action *Action) {
+	if action.Method != methodGet {
+		l.Bail(fmt.Errorf("controller: websocket action %q must be a GET route", action.Key))
+	}
+	if len(action.Results) > 0 && !action.Results.IsOnlyError() {
+		l.Bail(fmt.Errorf("controller: websocket action %q can only return an error", action.Key))
+	}
+}
+
 func (l *loader) loadActionParamName(param *parser.Param, nth int) string {
 	name := param.Name()
 	if name != "" {
@@ -335,6 +633,11 @@ func (l *loader) loadType(dt parser.Type, dec parser.Declaration) string {
 	}
 	// Standard library
 	if gois.StdLib(importPath) {
+		// Contexts are passed through from the request, so they're never part of
+		// the generated input
+		if importPath != "context" {
+			l.imports.AddStd(importPath)
+		}
 		dt := parser.Requalify(dt, imports.AssumedName(importPath))
 		return dt.String()
 	}
@@ -345,7 +648,7 @@ func (l *loader) loadType(dt parser.Type, dec parser.Declaration) string {
 }
 
 func (l *loader) loadActionInput(params []*ActionParam) string {
-	if len(params) == 1 && params[0].Kind == string(parser.KindStruct) {
+	if len(params) == 1 && params[0].Kind == string(parser.KindStruct) && !params[0].IsWebSocket() {
 		return params[0].Type
 	}
 	return l.loadActionInputStruct(params)
@@ -355,7 +658,7 @@ func (l *loader) loadActionInputStruct(params []*ActionParam) string {
 	b := new(strings.Builder)
 	b.WriteString("struct {")
 	for _, param := range params {
-		if param.IsContext() {
+		if param.IsContext() || param.IsWebSocket() {
 			continue
 		}
 		b.WriteString("\n")
@@ -376,6 +679,60 @@ func (l *loader) loadActionResults(results []*parser.Result) (outputs []*ActionR
 	return outputs
 }
 
+// StreamValue returns the type of the values that a streaming action produces.
+// Streaming actions return a receive-only channel (e.g. <-chan *Post) or an
+// iterator (e.g. func(yield func(*Post) bool)), optionally followed by an
+// error.
+func StreamValue(results []*parser.Result) (parser.Type, bool) {
+	switch len(results) {
+	case 1:
+	case 2:
+		if !results[1].IsError() {
+			return nil, false
+		}
+	default:
+		return nil, false
+	}
+	switch t := results[0].Type().(type) {
+	case *parser.ChanType:
+		if t.Receive() {
+			return t.Value(), true
+		}
+	case *parser.FuncType:
+		// func(yield func(T) bool)
+		params := t.Params()
+		if len(params) != 1 || len(t.Results()) != 0 {
+			return nil, false
+		}
+		yield, ok := params[0].(*parser.FuncType)
+		if !ok {
+			return nil, false
+		}
+		values, results := yield.Params(), yield.Results()
+		if len(values) == 1 && len(results) == 1 && results[0].String() == "bool" {
+			return values[0], true
+		}
+	}
+	return nil, false
+}
+
+// Stream results don't have a definition, so only load what's needed to call
+// the action and check the error
+func (l *loader) loadStreamResults(results []*parser.Result) (outputs ActionResults) {
+	for order, result := range results {
+		output := new(ActionResult)
+		output.Name = l.loadActionResultName(order, result)
+		output.Pascal = gotext.Pascal(output.Name)
+		output.Named = result.Named()
+		output.Snake = gotext.Snake(output.Name)
+		output.Type = parser.Unqualify(result.Type()).String()
+		output.Variable = l.loadActionResultVariable(order, result)
+		output.IsError = result.IsError()
+		outputs = append(outputs, output)
+	}
+	return outputs
+}
+
 func (l *loader) loadActionResult(order int, result *parser.Result) *ActionResult {
 	def, err := result.Definition()
 	if err != nil {
@@ -519,7 +876,12 @@ func (l *loader) loadProvider(controller *Controller, method *parser.Function) *
 	if err != nil {
 		l.Bail(err)
 	}
-	fnName := gotext.Camel("load " + controller.Name + " " + def.Name())
+	return l.wireProvider(controller, importPath, def.Name(), recv.Type().String())
+}
+
+// Wire up a function that loads the controller on each request
+func (l *loader) wireProvider(controller *Controller, importPath, name, dataType string) *di.Provider {
+	fnName := gotext.Camel("load " + controller.Name + " " + name)
 	provider, err := l.injector.Wire(&di.Function{
 		Name:    fnName,
 		Target:  l.module.Import("bud", "controller"),
@@ -528,7 +890,7 @@ func (l *loader) loadProvider(controller *Controller, method *parser.Function) *
 		Results: []di.Dependency{
 			&di.Type{
 				Import: importPath,
-				Type:   recv.Type().String(),
+				Type:   dataType,
 			},
 			&di.Error{},
 		},
@@ -536,12 +898,22 @@ func (l *loader) loadProvider(controller *Controller, method *parser.Function) *
 			{Import: "context", Type: "Context", Hoist: true},
 			{Import: "net/http", Type: "*Request"},
 			{Import: "net/http", Type: "ResponseWriter"},
+			{Import: "github.com/livebud/bud/package/session", Type: "*Session"},
+			{Import: "github.com/livebud/bud/package/websocket", Type: "*Conn"},
 		},
 		Aliases: di.Aliases{},
 	})
 	if err != nil {
 		l.Bail(err)
 	}
+	// Sessions are loaded into the request context by the session middleware
+	if provider.Variable("github.com/livebud/bud/package/session.*Session") != "" {
+		l.imports.Add("github.com/livebud/bud/package/session")
+	}
+	// WebSocket connections are upgraded before the controller is loaded
+	if provider.Variable("github.com/livebud/bud/package/websocket.*Conn") != "" {
+		l.imports.Add("github.com/livebud/bud/package/websocket")
+	}
 	// Add generated imports
 	for _, imp := range provider.Imports {
 		l.imports.AddNamed(imp.Name, imp.Path)
@@ -0,0 +1,161 @@
+package routes
+
+import (
+	"fmt"
+	"go/token"
+	"io/fs"
+	"strconv"
+	"strings"
+
+	"github.com/livebud/bud/framework/controller"
+	"github.com/livebud/bud/internal/bail"
+	"github.com/livebud/bud/internal/imports"
+	"github.com/livebud/bud/package/di"
+	"github.com/livebud/bud/package/gomod"
+	"github.com/livebud/bud/package/parser"
+	"github.com/livebud/bud/package/router/lex"
+	"github.com/matthewmueller/gotext"
+)
+
+// Load the path builders from the controllers
+func Load(fsys fs.FS, injector *di.Injector, module *gomod.Module, parser *parser.Parser) (*State, error) {
+	state, err := controller.Load(fsys, injector, module, parser)
+	if err != nil {
+		return nil, err
+	}
+	loader := &loader{imports: imports.New()}
+	return loader.Load(state)
+}
+
+type loader struct {
+	bail.Struct
+	imports *imports.Set
+}
+
+func (l *loader) Load(state *controller.State) (out *State, err error) {
+	defer l.Recover2(&err, "routes: unable to load path builders")
+	out = new(State)
+	out.Controller = l.loadController(state.Controller, 0)
+	l.imports.AddStd("fmt", "net/url", "strings")
+	out.Imports = l.imports.List()
+	return out, nil
+}
+
+func (l *loader) loadController(c *controller.Controller, depth int) *Controller {
+	out := &Controller{
+		Pascal: c.Pascal,
+		Field:  c.Last().Pascal(),
+		Camel:  gotext.Camel(string(c.Last())),
+		Depth:  depth,
+	}
+	for _, action := range c.Actions {
+		out.Actions = append(out.Actions, l.loadAction(action))
+	}
+	for _, child := range c.Controllers {
+		out.Controllers = append(out.Controllers, l.loadController(child, depth+1))
+	}
+	return out
+}
+
+func (l *loader) loadAction(action *controller.Action) *Action {
+	out := &Action{
+		Pascal: action.Pascal,
+		Camel:  action.Camel,
+		Method: strings.ToUpper(action.Method),
+		Route:  action.Route,
+	}
+	var goParts, scriptParts []string
+	var literal string // literal part of the route that hasn't been written yet
+	flush := func() {
+		if literal != "" {
+			goParts = append(goParts, strconv.Quote(literal))
+			scriptParts = append(scriptParts, strconv.Quote(literal))
+			literal = ""
+		}
+	}
+	lexer := lex.New(action.Route)
+	for {
+		tok := lexer.Next()
+		switch tok.Type {
+		case lex.EndToken:
+			flush()
+			if len(goParts) == 0 {
+				goParts = append(goParts, `"/"`)
+				scriptParts = append(scriptParts, `"/"`)
+			}
+			out.Go = strings.Join(goParts, " + ")
+			out.Script = strings.Join(scriptParts, " + ")
+			return out
+		case lex.ErrorToken:
+			l.Bail(fmt.Errorf("routes: %s", tok.Value))
+		case lex.SlotToken:
+			flush()
+			param := l.loadParam(action, tok.Slot())
+			out.Params = append(out.Params, param)
+			goParts = append(goParts, "segment("+param.Name+")")
+			scriptParts = append(scriptParts, "segment("+param.Name+")")
+		case lex.QuestionToken, lex.StarToken:
+			// Optional slots drop the text before them when they're empty
+			prefix := optionalPrefix(literal)
+			literal = strings.TrimSuffix(literal, prefix)
+			flush()
+			param := l.loadParam(action, tok.Slot())
+			param.Optional = true
+			out.Params = append(out.Params, param)
+			helper := "optional"
+			if tok.Type == lex.StarToken {
+				helper = "wildcard"
+			}
+			call := helper + "(" + strconv.Quote(prefix) + ", " + param.Name + ")"
+			goParts = append(goParts, call)
+			scriptParts = append(scriptParts, call)
+		default:
+			literal += tok.Value
+		}
+	}
+}
+
+// The prefix of an optional slot goes back to the last slash, or to the start
+// of the literal when it follows another slot (e.g. ":id.:format?")
+func optionalPrefix(literal string) string {
+	if i := strings.LastIndex(literal, "/"); i >= 0 {
+		return literal[i:]
+	}
+	return literal
+}
+
+// Load the parameter for the slot, typed by the action's parameter of the same
+// name when there is one
+func (l *loader) loadParam(action *controller.Action, slot string) *Param {
+	param := &Param{
+		Name:   gotext.Camel(slot),
+		Type:   "string",
+		Script: "string",
+	}
+	if token.IsKeyword(param.Name) || isReserved(param.Name) {
+		param.Name += "_"
+	}
+	for _, ap := range action.Params {
+		if ap.Snake != slot && ap.Name != slot {
+			continue
+		}
+		switch ap.Type {
+		case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "float32", "float64":
+			param.Type = ap.Type
+			param.Script = "number"
+		case "string":
+			param.Type = ap.Type
+		}
+	}
+	return param
+}
+
+// Names that would shadow the helpers or aren't valid in JS
+func isReserved(name string) bool {
+	switch name {
+	case "segment", "optional", "wildcard", "new", "delete", "function", "class", "this", "typeof", "void", "with", "yield", "let", "static", "enum", "await", "export", "extends", "super", "throw", "try", "catch", "finally", "while", "do", "instanceof", "in", "of", "null", "true", "false":
+		return true
+	default:
+		return false
+	}
+}
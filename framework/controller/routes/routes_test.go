@@ -0,0 +1,77 @@
+package routes_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/livebud/bud/internal/is"
+	"github.com/livebud/bud/internal/testcli"
+	"github.com/livebud/bud/internal/testdir"
+)
+
+func TestNoControllers(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	td := testdir.New(dir)
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(dir)
+	_, err := cli.Run(ctx, "build")
+	is.NoErr(err)
+	is.NoErr(td.NotExists("bud/internal/web/routes/routes.go"))
+	is.NoErr(td.NotExists("bud/view/routes.ts"))
+}
+
+func TestPathBuilders(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	td := testdir.New(dir)
+	td.Files["controller/controller.go"] = `
+		package controller
+		import "app.com/bud/internal/web/routes"
+		type Controller struct {}
+		func (c *Controller) Index() []string {
+			return []string{
+				routes.Posts.Show(10),
+				routes.Posts.Comments.Edit("10", "a b"),
+				routes.Posts.Files("a/b c"),
+				routes.Posts.Files(""),
+			}
+		}
+	`
+	td.Files["controller/posts/controller.go"] = `
+		package posts
+		type Controller struct {}
+		func (c *Controller) Show(id int) string { return "" }
+		// @route GET /files/:path*
+		func (c *Controller) Files(path string) string { return "" }
+	`
+	td.Files["controller/posts/comments/controller.go"] = `
+		package comments
+		type Controller struct {}
+		func (c *Controller) Edit() string { return "" }
+	`
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(dir)
+	app, err := cli.Start(ctx, "run")
+	is.NoErr(err)
+	defer app.Close()
+	res, err := app.GetJSON("/")
+	is.NoErr(err)
+	is.Equal(res.Status(), 200)
+	is.In(res.Body().String(), `["/posts/10","/posts/10/comments/a%20b/edit","/files/a/b%20c","/files"]`)
+	is.NoErr(app.Close())
+	// Go path builders
+	code, err := os.ReadFile(filepath.Join(dir, "bud/internal/web/routes/routes.go"))
+	is.NoErr(err)
+	is.In(string(code), `func (*PostsRoutes) Show(id int) string {`)
+	is.In(string(code), `func (*PostsCommentsRoutes) Edit(postID string, id string) string {`)
+	// JS path builders
+	script, err := os.ReadFile(filepath.Join(dir, "bud/view/routes.ts"))
+	is.NoErr(err)
+	is.In(string(script), `show: (id: number): string => "/posts/" + segment(id),`)
+	is.In(string(script), `files: (path?: string): string => "/files" + wildcard("/", path),`)
+}
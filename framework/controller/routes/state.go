@@ -0,0 +1,48 @@
+package routes
+
+import (
+	"strings"
+
+	"github.com/livebud/bud/internal/imports"
+)
+
+// State of the generated path builders
+type State struct {
+	Imports    []*imports.Import
+	Controller *Controller
+}
+
+// Controller has a path builder for each action
+type Controller struct {
+	Pascal      string // Type prefix (e.g. PostsComments)
+	Field       string // Field on the parent controller (e.g. Comments)
+	Camel       string // Key in the JS module (e.g. comments)
+	Depth       int    // Depth of nesting
+	Actions     []*Action
+	Controllers []*Controller
+}
+
+// Indent the controller's actions within the JS module
+func (c *Controller) Indent() string {
+	return strings.Repeat("  ", c.Depth+1)
+}
+
+// Action path builder
+type Action struct {
+	Pascal string // Method name (e.g. Show)
+	Camel  string // Key in the JS module (e.g. show)
+	Method string // HTTP method (e.g. GET)
+	Route  string // Route pattern (e.g. /posts/:id)
+	Params []*Param
+	Go     string // Go expression that builds the path
+	Script string // JS expression that builds the path
+}
+
+// Param for each route slot
+type Param struct {
+	Name   string // Go and JS variable name
+	Type   string // Go type
+	Script string // TypeScript type
+	// Optional slots can be left empty
+	Optional bool
+}
@@ -26,6 +26,7 @@ type Controller struct {
 	Route       string
 	Actions     []*Action
 	Controllers []*Controller
+	Middleware  *Middleware
 }
 
 func (c *Controller) Last() Name {
@@ -39,6 +40,18 @@ func (n Name) Pascal() string {
 	return gotext.Pascal(string(n))
 }
 
+// Middleware wraps the controller's actions and the actions of its nested
+// controllers
+type Middleware struct {
+	Provider *di.Provider
+	Stack    []string // Expressions that implement middleware.Middleware
+}
+
+// List the stack as arguments
+func (m *Middleware) List() string {
+	return strings.Join(m.Stack, ", ")
+}
+
 // Action is the target action state
 type Action struct {
 	Name        string
@@ -46,6 +59,7 @@ type Action struct {
 	Camel       string
 	Short       string
 	View        *View
+	ErrorPages  bool   // Render errors with the error pages when there's no view
 	Key         string // Key is an extension-less path
 	Route       string // Route to this action
 	Redirect    string
@@ -55,6 +69,8 @@ type Action struct {
 	HandlerFunc bool
 	Input       string
 	Results     ActionResults
+	Stream      bool // Stream the results from a channel or iterator
+	WebSocket   bool // Upgrade the request to a WebSocket connection
 	RespondJSON bool
 	RespondHTML bool
 	PropsKey    string
@@ -63,6 +79,7 @@ type Action struct {
 // View struct
 type View struct {
 	Route string
+	Path  string // Path to the view file (e.g. view/posts/index.svelte)
 }
 
 // ActionParam struct
@@ -80,6 +97,11 @@ func (ap *ActionParam) IsContext() bool {
 	return ap.Type == "context.Context"
 }
 
+// IsWebSocket is true for the upgraded *websocket.Conn
+func (ap *ActionParam) IsWebSocket() bool {
+	return ap.Variable == websocketConn
+}
+
 // ActionResults fn
 type ActionResults []*ActionResult
 
@@ -168,7 +190,7 @@ func (results ActionResults) ViewResult() string {
 		out.WriteString(":")
 		out.WriteString(results.Result())
 	}
-	out.WriteString(`},`)
+	out.WriteString(`}`)
 	return out.String()
 }
 
@@ -40,6 +40,30 @@ var coreFileGenerators = []*coreGenerator{
 		Import: "github.com/livebud/bud/framework/view",
 		Path:   "bud/internal/web/view/view.go",
 	},
+	{
+		Import: "github.com/livebud/bud/framework/openapi/document",
+		Path:   "bud/openapi.json",
+	},
+	{
+		Import: "github.com/livebud/bud/framework/openapi",
+		Path:   "bud/internal/web/openapi/openapi.go",
+	},
+	{
+		Import: "github.com/livebud/bud/framework/view/client",
+		Path:   "bud/view/client.ts",
+	},
+	{
+		Import: "github.com/livebud/bud/framework/controller/routes",
+		Path:   "bud/internal/web/routes/routes.go",
+	},
+	{
+		Import: "github.com/livebud/bud/framework/view/routes",
+		Path:   "bud/view/routes.ts",
+	},
+	{
+		Import: "github.com/livebud/bud/framework/command",
+		Path:   "bud/internal/command/command.go",
+	},
 	{
 		Import: "github.com/livebud/bud/framework/public",
 		Path:   "bud/internal/web/public/public.go",
@@ -0,0 +1,110 @@
+package document
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/livebud/bud/package/di"
+	"github.com/livebud/bud/package/genfs"
+	"github.com/livebud/bud/package/gomod"
+	"github.com/livebud/bud/package/parser"
+)
+
+// Document is an OpenAPI 3 document
+// https://spec.openapis.org/oas/v3.0.3
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       *Info                `json:"info"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components *Components          `json:"components,omitempty"`
+}
+
+// Info about the API
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// PathItem maps the lowercase HTTP methods to their operations
+type PathItem map[string]*Operation
+
+// Operation is a single action
+type Operation struct {
+	OperationID string               `json:"operationId"`
+	Summary     string               `json:"summary,omitempty"`
+	Parameters  []*Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+// Parameter in the path or query string
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema"`
+}
+
+// RequestBody of an operation
+type RequestBody struct {
+	Required bool                  `json:"required,omitempty"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+// Response of an operation
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+// MediaType of a request or response body
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components holds the schemas that are referenced by the operations
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas,omitempty"`
+}
+
+// Schema of a value
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+}
+
+// Generate the OpenAPI document
+func Generate(document *Document) ([]byte, error) {
+	return json.MarshalIndent(document, "", "  ")
+}
+
+// New OpenAPI document generator
+func New(injector *di.Injector, module *gomod.Module, parser *parser.Parser) *Generator {
+	return &Generator{injector, module, parser}
+}
+
+// Generator for bud/openapi.json
+type Generator struct {
+	injector *di.Injector
+	module   *gomod.Module
+	parser   *parser.Parser
+}
+
+func (g *Generator) GenerateFile(fsys genfs.FS, file *genfs.File) error {
+	document, err := Load(fsys, g.injector, g.module, g.parser)
+	if err != nil {
+		return fmt.Errorf("openapi: unable to load. %w", err)
+	}
+	code, err := Generate(document)
+	if err != nil {
+		return err
+	}
+	file.Data = code
+	return nil
+}
@@ -0,0 +1,481 @@
+package document
+
+import (
+	"fmt"
+	"io/fs"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/livebud/bud/framework/controller"
+	"github.com/livebud/bud/internal/bail"
+	"github.com/livebud/bud/internal/gois"
+	"github.com/livebud/bud/package/di"
+	"github.com/livebud/bud/package/gomod"
+	"github.com/livebud/bud/package/parser"
+	"github.com/livebud/bud/package/router/lex"
+	"github.com/matthewmueller/gotext"
+)
+
+// Load the OpenAPI document from the controllers
+func Load(fsys fs.FS, injector *di.Injector, module *gomod.Module, parser *parser.Parser) (*Document, error) {
+	state, err := controller.Load(fsys, injector, module, parser)
+	if err != nil {
+		return nil, err
+	}
+	loader := &loader{
+		module:  module,
+		parser:  parser,
+		schemas: map[string]*Schema{},
+		names:   map[string]string{},
+	}
+	return loader.Load(state)
+}
+
+type loader struct {
+	bail.Struct
+	module  *gomod.Module
+	parser  *parser.Parser
+	paths   map[string]*PathItem
+	schemas map[string]*Schema // component name => schema
+	names   map[string]string  // full type name => component name
+}
+
+func (l *loader) Load(state *controller.State) (document *Document, err error) {
+	defer l.Recover2(&err, "openapi: unable to load document")
+	l.paths = map[string]*PathItem{}
+	l.loadController(state.Controller)
+	// Don't generate a document if there aren't any actions
+	if len(l.paths) == 0 {
+		return nil, fs.ErrNotExist
+	}
+	document = &Document{
+		OpenAPI: "3.0.3",
+		Info: &Info{
+			Title:   path.Base(l.module.Import()),
+			Version: "0.0.0",
+		},
+		Paths: l.paths,
+	}
+	l.schemas["Error"] = &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"error": {Type: "string"},
+		},
+		Required: []string{"error"},
+	}
+	document.Components = &Components{Schemas: l.schemas}
+	return document, nil
+}
+
+func (l *loader) loadController(controller *controller.Controller) {
+	if len(controller.Actions) > 0 {
+		pkg, err := l.parser.Parse(path.Join("controller", controller.Path))
+		if err != nil {
+			l.Bail(err)
+		}
+		stct := pkg.Struct("Controller")
+		if stct == nil {
+			l.Bail(fmt.Errorf("openapi: unable to find the controller in %q", controller.Path))
+		}
+		for _, action := range controller.Actions {
+			method := stct.Method(action.Name)
+			if method == nil {
+				l.Bail(fmt.Errorf("openapi: unable to find the %s action in %q", action.Name, controller.Path))
+			}
+			l.loadAction(action, method)
+		}
+	}
+	for _, sub := range controller.Controllers {
+		l.loadController(sub)
+	}
+}
+
+func (l *loader) loadAction(action *controller.Action, method *parser.Function) {
+	route, slots := l.loadRoute(action.Route)
+	item, ok := l.paths[route]
+	if !ok {
+		item = &PathItem{}
+		l.paths[route] = item
+	}
+	operation := &Operation{
+		OperationID: OperationID(action),
+		Summary:     strings.Split(method.Doc(), "\n")[0],
+		Responses:   map[string]*Response{},
+	}
+	(*item)[strings.ToLower(action.Method)] = operation
+	// Handler funcs can't be described
+	if action.HandlerFunc {
+		operation.Responses["default"] = &Response{Description: "Custom response"}
+		return
+	}
+	l.loadInputs(operation, action, method, slots)
+	if action.WebSocket {
+		operation.Responses["101"] = &Response{Description: http.StatusText(http.StatusSwitchingProtocols)}
+		return
+	}
+	if action.Stream {
+		l.loadStreamResponses(operation, method)
+		return
+	}
+	l.loadResponses(operation, method)
+}
+
+// OperationID uniquely identifies the action (e.g. posts/show => postsShow)
+func OperationID(action *controller.Action) string {
+	return gotext.Camel(strings.ReplaceAll(action.Key, "/", " "))
+}
+
+// Turn the route into an OpenAPI path (e.g. /posts/:id => /posts/{id})
+func (l *loader) loadRoute(route string) (string, []string) {
+	lexer := lex.New(route)
+	out := new(strings.Builder)
+	var slots []string
+	for {
+		token := lexer.Next()
+		switch token.Type {
+		case lex.EndToken:
+			return out.String(), slots
+		case lex.ErrorToken:
+			l.Bail(fmt.Errorf("openapi: %s", token.Value))
+		case lex.SlotToken, lex.QuestionToken, lex.StarToken:
+			slot := token.Slot()
+			slots = append(slots, slot)
+			out.WriteString("{" + slot + "}")
+		default:
+			out.WriteString(token.Value)
+		}
+	}
+}
+
+// Load the path parameters, query parameters and request body
+func (l *loader) loadInputs(operation *Operation, action *controller.Action, method *parser.Function, slots []string) {
+	properties := map[string]*Schema{}
+	var required []string
+	params := method.Params()
+	for _, param := range params {
+		isContext, err := parser.IsImportType(param.Type(), "context", "Context")
+		if err != nil {
+			l.Bail(err)
+		} else if isContext {
+			continue
+		}
+		// WebSocket connections are upgraded from the request
+		isConn, err := parser.IsImportType(param.Type(), "github.com/livebud/bud/package/websocket", "Conn")
+		if err != nil {
+			l.Bail(err)
+		} else if isConn {
+			continue
+		}
+		// A single struct input's fields are the inputs
+		if len(params) == 1 {
+			if stct := l.findStruct(param.Type()); stct != nil {
+				schema := l.loadStruct(stct)
+				for name, property := range schema.Properties {
+					properties[name] = property
+				}
+				required = append(required, schema.Required...)
+				continue
+			}
+		}
+		properties[gotext.Lower(gotext.Snake(param.Name()))] = l.loadSchema(param.Type())
+	}
+	// Slots are path parameters. Like request.Unmarshal, fields are matched to
+	// slots case-insensitively.
+	for _, slot := range slots {
+		schema := &Schema{Type: "string"}
+		if name, ok := findProperty(properties, slot); ok {
+			schema = properties[name]
+			delete(properties, name)
+		}
+		operation.Parameters = append(operation.Parameters, &Parameter{
+			Name:     slot,
+			In:       "path",
+			Required: true,
+			Schema:   schema,
+		})
+	}
+	if len(properties) == 0 {
+		return
+	}
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	// GET and DELETE requests pass their inputs in the query string
+	switch action.Method {
+	case "Get", "Delete":
+		for _, name := range names {
+			operation.Parameters = append(operation.Parameters, &Parameter{
+				Name:     name,
+				In:       "query",
+				Required: contains(required, name),
+				Schema:   properties[name],
+			})
+		}
+		return
+	}
+	schema := &Schema{
+		Type:       "object",
+		Properties: properties,
+	}
+	for _, name := range names {
+		if contains(required, name) {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	operation.RequestBody = &RequestBody{
+		Content: map[string]*MediaType{
+			"application/json":                  {Schema: schema},
+			"application/x-www-form-urlencoded": {Schema: schema},
+		},
+	}
+}
+
+func (l *loader) loadResponses(operation *Operation, method *parser.Function) {
+	operation.Responses["default"] = &Response{
+		Description: "Error",
+		Content: map[string]*MediaType{
+			"application/json": {Schema: &Schema{Ref: "#/components/schemas/Error"}},
+		},
+	}
+	var results []*parser.Result
+	for _, result := range method.Results() {
+		if !result.IsError() {
+			results = append(results, result)
+		}
+	}
+	switch {
+	case len(results) == 0:
+		operation.Responses["204"] = &Response{Description: http.StatusText(http.StatusNoContent)}
+		return
+	case len(results) == 1:
+		operation.Responses["200"] = l.jsonResponse(l.loadSchema(results[0].Type()))
+		return
+	}
+	// Multiple named results are an object, otherwise they're an array
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for _, result := range results {
+		if !result.Named() {
+			operation.Responses["200"] = l.jsonResponse(&Schema{Type: "array", Items: &Schema{}})
+			return
+		}
+		schema.Properties[gotext.Snake(result.Name())] = l.loadSchema(result.Type())
+	}
+	operation.Responses["200"] = l.jsonResponse(schema)
+}
+
+// Streams respond with a server-sent event or a line of JSON per value
+func (l *loader) loadStreamResponses(operation *Operation, method *parser.Function) {
+	operation.Responses["default"] = &Response{
+		Description: "Error",
+		Content: map[string]*MediaType{
+			"application/json": {Schema: &Schema{Ref: "#/components/schemas/Error"}},
+		},
+	}
+	value, ok := controller.StreamValue(method.Results())
+	if !ok {
+		l.Bail(fmt.Errorf("openapi: %s doesn't return a stream", method.Name()))
+	}
+	schema := l.loadSchema(value)
+	operation.Responses["200"] = &Response{
+		Description: http.StatusText(http.StatusOK),
+		Content: map[string]*MediaType{
+			"text/event-stream":    {Schema: schema},
+			"application/x-ndjson": {Schema: schema},
+		},
+	}
+}
+
+func (l *loader) jsonResponse(schema *Schema) *Response {
+	return &Response{
+		Description: http.StatusText(http.StatusOK),
+		Content: map[string]*MediaType{
+			"application/json": {Schema: schema},
+		},
+	}
+}
+
+// Find the struct that the type refers to, if any
+func (l *loader) findStruct(t parser.Type) *parser.Struct {
+	if star, ok := t.(*parser.StarType); ok {
+		t = star.Inner()
+	}
+	if isStdLib(t) {
+		return nil
+	}
+	def, err := parser.Definition(t)
+	if err != nil || def.Kind() != parser.KindStruct {
+		return nil
+	}
+	return def.Package().Struct(def.Name())
+}
+
+// Load the schema of a Go type
+func (l *loader) loadSchema(t parser.Type) *Schema {
+	switch t := t.(type) {
+	case *parser.StarType:
+		schema := l.loadSchema(t.Inner())
+		if schema.Ref == "" {
+			schema.Nullable = true
+		}
+		return schema
+	case *parser.ArrayType:
+		if t.Inner().String() == "byte" {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: l.loadSchema(t.Inner())}
+	case *parser.MapType:
+		return &Schema{Type: "object", AdditionalProperties: l.loadSchema(t.Value())}
+	case *parser.IdentType:
+		if schema := builtinSchema(t.String()); schema != nil {
+			return schema
+		}
+	case *parser.SelectorType:
+		if isStdLib(t) {
+			return stdlibSchema(t.String())
+		}
+	default:
+		return &Schema{}
+	}
+	def, err := parser.Definition(t)
+	if err != nil {
+		l.Bail(fmt.Errorf("openapi: unable to find the definition of %s. %w", t, err))
+	}
+	switch def.Kind() {
+	case parser.KindStruct:
+		return l.loadComponent(def)
+	case parser.KindTypeSpec:
+		if spec, ok := def.(*parser.TypeSpec); ok {
+			return l.loadSchema(spec.Type())
+		}
+	}
+	return &Schema{}
+}
+
+// Load a named struct as a component and reference it
+func (l *loader) loadComponent(def parser.Declaration) *Schema {
+	importPath, err := def.Package().Import()
+	if err != nil {
+		l.Bail(err)
+	}
+	fullName := importPath + "." + def.Name()
+	if name, ok := l.names[fullName]; ok {
+		return &Schema{Ref: "#/components/schemas/" + name}
+	}
+	name := def.Name()
+	if _, ok := l.schemas[name]; ok {
+		name = gotext.Pascal(def.Package().Name() + " " + def.Name())
+	}
+	l.names[fullName] = name
+	// Reserve the name before loading the struct to support recursive types
+	l.schemas[name] = &Schema{}
+	stct := def.Package().Struct(def.Name())
+	if stct == nil {
+		l.Bail(fmt.Errorf("openapi: unable to find struct %s", fullName))
+	}
+	l.schemas[name] = l.loadStruct(stct)
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// Load the struct's fields into an object schema
+func (l *loader) loadStruct(stct *parser.Struct) *Schema {
+	schema := &Schema{
+		Type:       "object",
+		Properties: map[string]*Schema{},
+	}
+	for _, field := range stct.PublicFields() {
+		tags, err := field.Tags()
+		if err != nil {
+			l.Bail(err)
+		}
+		name := jsonName(field.Name(), tags.Get("json"))
+		if name == "-" {
+			continue
+		}
+		schema.Properties[name] = l.loadSchema(field.Type())
+		if contains(strings.Split(tags.Get("validate"), ","), "required") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	sort.Strings(schema.Required)
+	return schema
+}
+
+// jsonName follows encoding/json's naming rules
+func jsonName(fieldName, tag string) string {
+	if name := strings.Split(tag, ",")[0]; name != "" {
+		return name
+	}
+	return fieldName
+}
+
+func isStdLib(t parser.Type) bool {
+	importPath, err := parser.ImportPath(t)
+	if err != nil {
+		return false
+	}
+	return gois.StdLib(importPath)
+}
+
+func builtinSchema(name string) *Schema {
+	switch name {
+	case "string":
+		return &Schema{Type: "string"}
+	case "bool":
+		return &Schema{Type: "boolean"}
+	case "int8", "int16", "int32", "uint8", "uint16", "uint32", "byte", "rune":
+		return &Schema{Type: "integer", Format: "int32"}
+	case "int", "int64", "uint", "uint64":
+		return &Schema{Type: "integer", Format: "int64"}
+	case "float32":
+		return &Schema{Type: "number", Format: "float"}
+	case "float64":
+		return &Schema{Type: "number", Format: "double"}
+	case "any":
+		return &Schema{}
+	default:
+		return nil
+	}
+}
+
+func stdlibSchema(name string) *Schema {
+	switch name {
+	case "time.Time":
+		return &Schema{Type: "string", Format: "date-time"}
+	case "multipart.FileHeader":
+		return &Schema{Type: "string", Format: "binary"}
+	default:
+		return &Schema{}
+	}
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// findProperty finds the property that matches the slot, preferring an exact
+// match over a case-insensitive one
+func findProperty(properties map[string]*Schema, slot string) (string, bool) {
+	if _, ok := properties[slot]; ok {
+		return slot, true
+	}
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if strings.EqualFold(name, slot) {
+			return name, true
+		}
+	}
+	return "", false
+}
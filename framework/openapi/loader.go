@@ -0,0 +1,30 @@
+package openapi
+
+import (
+	"io/fs"
+	"strconv"
+
+	"github.com/livebud/bud/internal/imports"
+)
+
+// Route that serves the OpenAPI document
+const Route = "/openapi.json"
+
+// Path to the generated OpenAPI document
+const Path = "bud/openapi.json"
+
+func Load(fsys fs.FS) (*State, error) {
+	// Returns fs.ErrNotExist when there aren't any actions to describe
+	document, err := fs.ReadFile(fsys, Path)
+	if err != nil {
+		return nil, err
+	}
+	imset := imports.New()
+	imset.AddStd("net/http")
+	imset.AddNamed("router", "github.com/livebud/bud/package/router")
+	return &State{
+		Imports:  imset.List(),
+		Route:    Route,
+		Document: strconv.Quote(string(document)),
+	}, nil
+}
@@ -0,0 +1,41 @@
+package openapi
+
+import (
+	_ "embed"
+	"fmt"
+
+	"github.com/livebud/bud/internal/gotemplate"
+	"github.com/livebud/bud/package/genfs"
+)
+
+//go:embed openapi.gotext
+var template string
+
+var generator = gotemplate.MustParse("framework/openapi/openapi.gotext", template)
+
+// Generate the OpenAPI handler from state
+func Generate(state *State) ([]byte, error) {
+	return generator.Generate(state)
+}
+
+// New OpenAPI handler generator
+func New() *Generator {
+	return &Generator{}
+}
+
+// Generator serves the OpenAPI document from the app
+type Generator struct {
+}
+
+func (g *Generator) GenerateFile(fsys genfs.FS, file *genfs.File) error {
+	state, err := Load(fsys)
+	if err != nil {
+		return fmt.Errorf("openapi: unable to load. %w", err)
+	}
+	code, err := Generate(state)
+	if err != nil {
+		return err
+	}
+	file.Data = code
+	return nil
+}
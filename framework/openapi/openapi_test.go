@@ -0,0 +1,157 @@
+package openapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/livebud/bud/internal/is"
+	"github.com/livebud/bud/internal/testcli"
+	"github.com/livebud/bud/internal/testdir"
+)
+
+const usersController = `
+	package users
+	import "context"
+	type Controller struct {}
+	type User struct {
+		ID      int      ` + "`" + `json:"id"` + "`" + `
+		Name    string   ` + "`" + `json:"name" validate:"required"` + "`" + `
+		Friends []*User  ` + "`" + `json:"friends,omitempty"` + "`" + `
+		Hidden  string   ` + "`" + `json:"-"` + "`" + `
+	}
+	// Index lists users
+	func (c *Controller) Index(ctx context.Context, page int) ([]*User, error) {
+		return nil, nil
+	}
+	// Show a user
+	func (c *Controller) Show(id int) (*User, error) {
+		return nil, nil
+	}
+	// Create a user
+	func (c *Controller) Create(in *User) (*User, error) {
+		return in, nil
+	}
+	// Delete a user
+	func (c *Controller) Delete(id int) error {
+		return nil
+	}
+`
+
+func TestNoControllers(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	td := testdir.New(dir)
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(dir)
+	_, err := cli.Run(ctx, "build")
+	is.NoErr(err)
+	is.NoErr(td.NotExists("bud/openapi.json"))
+	is.NoErr(td.NotExists("bud/internal/web/openapi/openapi.go"))
+}
+
+func TestToolOpenAPI(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	td := testdir.New(dir)
+	td.Files["controller/users/controller.go"] = usersController
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(dir)
+	result, err := cli.Run(ctx, "tool", "openapi")
+	is.NoErr(err)
+	var document struct {
+		OpenAPI string                                `json:"openapi"`
+		Paths   map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	is.NoErr(json.Unmarshal([]byte(result.Stdout()), &document))
+	is.Equal(document.OpenAPI, "3.0.3")
+	is.Equal(len(document.Paths), 2)
+	is.Equal(len(document.Paths["/users"]), 2)
+	is.Equal(len(document.Paths["/users/{id}"]), 2)
+	is.In(result.Stdout(), `"operationId": "usersShow"`)
+	is.In(result.Stdout(), `"summary": "Show a user"`)
+	is.In(result.Stdout(), `"$ref": "#/components/schemas/User"`)
+	is.NoErr(td.Exists("bud/openapi.json"))
+}
+
+func TestServeOpenAPI(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	td := testdir.New(dir)
+	td.Files["controller/users/controller.go"] = usersController
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(dir)
+	app, err := cli.Start(ctx, "run")
+	is.NoErr(err)
+	defer app.Close()
+	res, err := app.Get("/openapi.json")
+	is.NoErr(err)
+	is.Equal(res.Status(), 200)
+	is.Equal(res.Header("Content-Type"), "application/json")
+	var document struct {
+		Components struct {
+			Schemas map[string]struct {
+				Properties map[string]json.RawMessage `json:"properties"`
+				Required   []string                   `json:"required"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	is.NoErr(json.Unmarshal(res.Body().Bytes(), &document))
+	user := document.Components.Schemas["User"]
+	is.Equal(len(user.Properties), 3)
+	is.Equal(user.Required, []string{"name"})
+	is.In(string(user.Properties["friends"]), `"#/components/schemas/User"`)
+	is.NoErr(app.Close())
+}
+
+func TestSlotCase(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	td := testdir.New(dir)
+	td.Files["controller/posts/controller.go"] = `
+		package posts
+		type Controller struct {}
+		type UpdatePost struct {
+			ID    int
+			Title string
+		}
+		func (c *Controller) Update(in *UpdatePost) error {
+			return nil
+		}
+	`
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(dir)
+	result, err := cli.Run(ctx, "tool", "openapi")
+	is.NoErr(err)
+	var document struct {
+		Paths map[string]map[string]struct {
+			Parameters []struct {
+				Name   string          `json:"name"`
+				In     string          `json:"in"`
+				Schema json.RawMessage `json:"schema"`
+			} `json:"parameters"`
+			RequestBody struct {
+				Content map[string]struct {
+					Schema struct {
+						Properties map[string]json.RawMessage `json:"properties"`
+					} `json:"schema"`
+				} `json:"content"`
+			} `json:"requestBody"`
+		} `json:"paths"`
+	}
+	is.NoErr(json.Unmarshal([]byte(result.Stdout()), &document))
+	update := document.Paths["/posts/{id}"]["patch"]
+	// The ID field is the id slot, so it's only a path parameter
+	is.Equal(len(update.Parameters), 1)
+	is.Equal(update.Parameters[0].Name, "id")
+	is.Equal(update.Parameters[0].In, "path")
+	is.In(string(update.Parameters[0].Schema), `"integer"`)
+	for _, content := range update.RequestBody.Content {
+		is.Equal(len(content.Schema.Properties), 1)
+		is.True(content.Schema.Properties["Title"] != nil)
+	}
+}
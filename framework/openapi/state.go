@@ -0,0 +1,9 @@
+package openapi
+
+import "github.com/livebud/bud/internal/imports"
+
+type State struct {
+	Imports  []*imports.Import
+	Route    string
+	Document string // Quoted JSON document
+}
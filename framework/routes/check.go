@@ -0,0 +1,186 @@
+package routes
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/livebud/bud/package/router"
+	"github.com/livebud/bud/package/router/lex"
+	"github.com/livebud/bud/package/router/radix"
+)
+
+// Conflict between two routes with the same method
+type Conflict struct {
+	Method string
+	Route  *Route // Route that's reported
+	Other  *Route // Route that it conflicts with
+	// Shadow is true when the route is reachable, but it takes requests that
+	// would otherwise go to the other route (e.g. the public file
+	// /users/new shadows the action /users/:id). Conflicts that aren't shadows
+	// can't be routed.
+	Shadow bool
+}
+
+func (c *Conflict) Error() string {
+	if c.Shadow {
+		return fmt.Sprintf("routes: %s %s in %s shadows %s %s in %s", c.Method, c.Route.Route, c.Route.Source(), c.Method, c.Other.Route, c.Other.Source())
+	}
+	if c.Route.Route == c.Other.Route {
+		return fmt.Sprintf("routes: %s %s is defined in both %s and %s", c.Method, c.Route.Route, c.Route.Source(), c.Other.Source())
+	}
+	return fmt.Sprintf("routes: %s %s in %s is ambiguous with %s %s in %s", c.Method, c.Route.Route, c.Route.Source(), c.Method, c.Other.Route, c.Other.Source())
+}
+
+// Check the routes for conflicts. Routes that can't be told apart are
+// conflicts. Static routes that take requests from a route of another kind
+// (e.g. a public file and a controller action) are shadows.
+func Check(routes []*Route) (conflicts []*Conflict) {
+	methods := map[string][]*Route{}
+	for _, route := range routes {
+		methods[route.Method] = append(methods[route.Method], route)
+	}
+	for method, routes := range methods {
+		conflicts = append(conflicts, check(method, routes)...)
+	}
+	sort.SliceStable(conflicts, func(i, j int) bool {
+		a, b := conflicts[i], conflicts[j]
+		if a.Route.Route != b.Route.Route {
+			return a.Route.Route < b.Route.Route
+		}
+		if a.Method != b.Method {
+			return a.Method < b.Method
+		}
+		return a.Other.Route < b.Other.Route
+	})
+	return conflicts
+}
+
+// Check the routes of a single method
+func check(method string, routes []*Route) (conflicts []*Conflict) {
+	tree := radix.New()
+	// Inserted routes by the routes they expand into, since a route with an
+	// optional slot can conflict with another route through either expansion
+	inserted := map[string]*Route{}
+	var static []*Route
+	for _, route := range routes {
+		pattern := router.Normalize(route.Route)
+		expanded, err := radix.Expand(pattern)
+		if err != nil {
+			// Invalid routes are reported by the router
+			continue
+		}
+		if existing := findInserted(inserted, expanded); existing != nil {
+			if existing.Source() != route.Source() {
+				conflicts = append(conflicts, &Conflict{method, route, existing, false})
+			}
+			continue
+		}
+		if err := tree.Insert(pattern, http.NotFoundHandler()); err != nil {
+			var ambiguous *radix.AmbiguousError
+			if errors.As(err, &ambiguous) {
+				if existing := findAmbiguous(inserted, ambiguous.Existing, pattern); existing != nil {
+					conflicts = append(conflicts, &Conflict{method, route, existing, false})
+				}
+			}
+			continue
+		}
+		for _, expansion := range expanded {
+			inserted[expansion] = route
+		}
+		if isStatic(pattern) {
+			static = append(static, route)
+		}
+	}
+	// Static routes win over routes with slots, so check if any of the static
+	// routes would have matched a route of another kind
+	for _, route := range static {
+		pattern := router.Normalize(route.Route)
+		shadowed := map[*Route]bool{}
+		for other, otherRoute := range inserted {
+			if other == pattern || otherRoute.kind() == route.kind() || shadowed[otherRoute] {
+				continue
+			}
+			if matches(other, pattern) {
+				shadowed[otherRoute] = true
+				conflicts = append(conflicts, &Conflict{method, route, otherRoute, true})
+			}
+		}
+	}
+	return conflicts
+}
+
+// Find the inserted route that already took one of the expanded routes
+func findInserted(inserted map[string]*Route, expanded []string) *Route {
+	for _, route := range expanded {
+		if existing, ok := inserted[route]; ok {
+			return existing
+		}
+	}
+	return nil
+}
+
+// Find the inserted route that the pattern is ambiguous with. The tree reports
+// the existing route as it was inserted, but that route is empty when the
+// conflict is with a node that was split, so then the inserted routes are
+// tried one by one.
+func findAmbiguous(inserted map[string]*Route, existing, pattern string) *Route {
+	if expanded, err := radix.Expand(existing); err == nil {
+		if route := findInserted(inserted, expanded); route != nil {
+			return route
+		}
+	}
+	others := make([]string, 0, len(inserted))
+	for other := range inserted {
+		others = append(others, other)
+	}
+	sort.Strings(others)
+	for _, other := range others {
+		tree := radix.New()
+		if err := tree.Insert(other, http.NotFoundHandler()); err != nil {
+			continue
+		}
+		var ambiguous *radix.AmbiguousError
+		if err := tree.Insert(pattern, http.NotFoundHandler()); errors.As(err, &ambiguous) {
+			return inserted[other]
+		}
+	}
+	return nil
+}
+
+// Static routes don't have any slots
+func isStatic(route string) bool {
+	lexer := lex.New(route)
+	for {
+		token := lexer.Next()
+		switch token.Type {
+		case lex.EndToken:
+			return true
+		case lex.SlotToken, lex.QuestionToken, lex.StarToken, lex.ErrorToken:
+			return false
+		}
+	}
+}
+
+// Check if the route would match the path on its own
+func matches(route, path string) bool {
+	tree := radix.New()
+	if err := tree.Insert(route, http.NotFoundHandler()); err != nil {
+		return false
+	}
+	_, ok := tree.Match(path)
+	return ok
+}
+
+// Kind of source that handles the route
+func (r *Route) kind() string {
+	switch {
+	case r.Action != "":
+		return "action"
+	case r.View != "":
+		return "view"
+	default:
+		return "file"
+	}
+}
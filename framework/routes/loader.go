@@ -0,0 +1,105 @@
+package routes
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"sort"
+
+	"github.com/livebud/bud/framework"
+	"github.com/livebud/bud/framework/openapi"
+	"github.com/livebud/bud/framework/public"
+	"github.com/livebud/bud/internal/bail"
+	"github.com/livebud/bud/internal/entrypoint"
+)
+
+// Load the routes of the views and public files alongside the routes of the
+// controller actions
+func Load(fsys fs.FS, actions []*Route) (*State, error) {
+	loader := &loader{
+		fsys:   fsys,
+		routes: actions,
+	}
+	return loader.Load()
+}
+
+type loader struct {
+	bail.Struct
+	fsys   fs.FS
+	routes []*Route
+}
+
+func (l *loader) Load() (state *State, err error) {
+	defer l.Recover2(&err, "routes: unable to load")
+	l.loadOpenAPI()
+	l.loadViews()
+	l.loadPublic()
+	if len(l.routes) == 0 {
+		return nil, fs.ErrNotExist
+	}
+	sort.SliceStable(l.routes, func(i, j int) bool {
+		if l.routes[i].Route != l.routes[j].Route {
+			return l.routes[i].Route < l.routes[j].Route
+		}
+		return l.routes[i].Method < l.routes[j].Method
+	})
+	return &State{Routes: l.routes}, nil
+}
+
+func (l *loader) loadOpenAPI() {
+	if _, err := fs.Stat(l.fsys, openapi.Path); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return
+		}
+		l.Bail(err)
+	}
+	l.routes = append(l.routes, &Route{
+		Method: http.MethodGet,
+		Route:  openapi.Route,
+		File:   openapi.Path,
+	})
+}
+
+// Load the client-side view routes. These are the same routes that the view
+// handler registers when assets aren't embedded.
+func (l *loader) loadViews() {
+	views, err := entrypoint.List(l.fsys, "view")
+	if err != nil {
+		l.Bail(err)
+	}
+	for _, view := range views {
+		l.routes = append(l.routes, &Route{
+			Method: http.MethodGet,
+			Route:  "/" + view.Client,
+			View:   string(view.Page),
+		}, &Route{
+			Method: http.MethodGet,
+			Route:  "/bud/" + string(view.Page),
+			View:   string(view.Page),
+		})
+	}
+	if len(views) > 0 {
+		l.routes = append(l.routes, &Route{
+			Method: http.MethodGet,
+			Route:  "/bud/node_modules/:module*",
+			File:   "node_modules",
+		})
+	}
+}
+
+func (l *loader) loadPublic() {
+	state, err := public.Load(l.fsys, &framework.Flag{})
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return
+		}
+		l.Bail(err)
+	}
+	for _, file := range state.Files {
+		l.routes = append(l.routes, &Route{
+			Method: http.MethodGet,
+			Route:  file.Route,
+			File:   file.Path,
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package routes_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/livebud/bud/framework/routes"
+	"github.com/livebud/bud/internal/is"
+	"github.com/livebud/bud/internal/testcli"
+	"github.com/livebud/bud/internal/testdir"
+	"github.com/livebud/bud/internal/versions"
+)
+
+func TestRoutes(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	td := testdir.New(dir)
+	td.Files["controller/posts/controller.go"] = `
+		package posts
+		type Controller struct {}
+		func (c *Controller) Index() []string { return nil }
+		func (c *Controller) Create(title string) {}
+		// @route POST /posts/:id/publish
+		func (c *Controller) Publish(id int) {}
+	`
+	td.Files["view/posts/index.svelte"] = `<h1>posts</h1>`
+	td.Files["public/favicon.ico"] = `ico`
+	td.NodeModules["svelte"] = versions.Svelte
+	td.NodeModules["livebud"] = "*"
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(dir)
+	result, err := cli.Run(ctx, "routes")
+	is.NoErr(err)
+	is.In(result.Stdout(), "METHOD")
+	is.In(result.Stdout(), "GET     /posts ")
+	is.In(result.Stdout(), "POST    /posts ")
+	is.In(result.Stdout(), "POST    /posts/:id<int>/publish")
+	is.In(result.Stdout(), "GET     /favicon.ico")
+	is.In(result.Stdout(), "GET     /openapi.json")
+}
+
+func TestRoutesJSON(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	td := testdir.New(dir)
+	td.Files["controller/controller.go"] = `
+		package controller
+		type Controller struct {}
+		func (c *Controller) Index() string { return "" }
+	`
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(dir)
+	result, err := cli.Run(ctx, "routes", "--json")
+	is.NoErr(err)
+	var routes []struct {
+		Method string `json:"method"`
+		Route  string `json:"route"`
+	}
+	is.NoErr(json.Unmarshal([]byte(result.Stdout()), &routes))
+	is.Equal(len(routes), 2)
+	is.Equal(routes[0].Method, "GET")
+	is.Equal(routes[0].Route, "/")
+	is.Equal(routes[1].Route, "/openapi.json")
+}
+
+// Apps without any routes list the welcome page
+func TestWelcomeRoutes(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	td := testdir.New(dir)
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(dir)
+	result, err := cli.Run(ctx, "routes")
+	is.NoErr(err)
+	is.In(result.Stdout(), "GET     / ")
+}
+
+func TestCheckDuplicate(t *testing.T) {
+	is := is.New(t)
+	conflicts := routes.Check([]*routes.Route{
+		{Method: "GET", Route: "/users/new", Action: "users/new"},
+		{Method: "GET", Route: "/users/new", File: "public/users/new"},
+		{Method: "POST", Route: "/users/new", Action: "users/create"},
+	})
+	is.Equal(len(conflicts), 1)
+	is.Equal(conflicts[0].Shadow, false)
+	is.Equal(conflicts[0].Error(), `routes: GET /users/new is defined in both public/users/new and users/new`)
+}
+
+func TestCheckAmbiguous(t *testing.T) {
+	is := is.New(t)
+	conflicts := routes.Check([]*routes.Route{
+		{Method: "GET", Route: "/:id", Action: "show"},
+		{Method: "GET", Route: "/:slug", Action: "pages/show"},
+		{Method: "GET", Route: "/:id<int>", Action: "posts/show"},
+	})
+	is.Equal(len(conflicts), 1)
+	is.Equal(conflicts[0].Shadow, false)
+	is.Equal(conflicts[0].Error(), `routes: GET /:slug in pages/show is ambiguous with GET /:id in show`)
+}
+
+func TestCheckShadow(t *testing.T) {
+	is := is.New(t)
+	conflicts := routes.Check([]*routes.Route{
+		{Method: "GET", Route: "/users/:id", Action: "users/show", View: "view/users/show.svelte"},
+		{Method: "GET", Route: "/users/new", Action: "users/new"},
+		{Method: "GET", Route: "/users/avatar.png", File: "public/users/avatar.png"},
+		{Method: "GET", Route: "/users/settings", File: "public/users/settings"},
+		{Method: "GET", Route: "/posts/:id<int>", Action: "posts/show"},
+		{Method: "GET", Route: "/posts/about", File: "public/posts/about"},
+	})
+	// Static actions next to slots in the same controller are expected and
+	// constraints that don't match aren't shadowed
+	is.Equal(len(conflicts), 1)
+	is.Equal(conflicts[0].Shadow, true)
+	is.Equal(conflicts[0].Error(), `routes: GET /users/settings in public/users/settings shadows GET /users/:id in users/show (view/users/show.svelte)`)
+}
+
+func TestCheckConstraintCase(t *testing.T) {
+	is := is.New(t)
+	conflicts := routes.Check([]*routes.Route{
+		{Method: "GET", Route: "/codes/:code<[A-Z]+>", Action: "codes/show"},
+		{Method: "GET", Route: "/codes/about", File: "public/codes/about"},
+	})
+	// Constraints are case-sensitive, so the file isn't shadowed
+	is.Equal(len(conflicts), 0)
+}
+
+func TestCheckOptional(t *testing.T) {
+	is := is.New(t)
+	conflicts := routes.Check([]*routes.Route{
+		{Method: "GET", Route: "/posts/:id?", Action: "posts/show"},
+		{Method: "GET", Route: "/posts", File: "public/posts"},
+		{Method: "GET", Route: "/posts/:slug", Action: "articles/show"},
+	})
+	// Both of the optional route's expansions are checked
+	is.Equal(len(conflicts), 2)
+	is.Equal(conflicts[0].Error(), `routes: GET /posts in public/posts is ambiguous with GET /posts/:id? in posts/show`)
+	is.Equal(conflicts[1].Error(), `routes: GET /posts/:slug in articles/show is ambiguous with GET /posts/:id? in posts/show`)
+}
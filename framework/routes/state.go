@@ -0,0 +1,29 @@
+package routes
+
+// State is the list of routes the app registers
+type State struct {
+	Routes []*Route
+}
+
+// Route is a registered route and the source that handles it
+type Route struct {
+	Method string
+	Route  string
+	Action string // Controller action key (e.g. posts/index)
+	View   string // View file (e.g. view/posts/index.svelte)
+	File   string // Public file (e.g. public/favicon.ico)
+}
+
+// Source of the route
+func (r *Route) Source() string {
+	switch {
+	case r.Action != "" && r.View != "":
+		return r.Action + " (" + r.View + ")"
+	case r.Action != "":
+		return r.Action
+	case r.View != "":
+		return r.View
+	default:
+		return r.File
+	}
+}
@@ -0,0 +1,51 @@
+package client
+
+import (
+	_ "embed"
+	"fmt"
+
+	"github.com/livebud/bud/internal/gotemplate"
+	"github.com/livebud/bud/package/di"
+	"github.com/livebud/bud/package/genfs"
+	"github.com/livebud/bud/package/gomod"
+	"github.com/livebud/bud/package/parser"
+)
+
+// Path to the generated TypeScript client. Views import it from their
+// relative path (e.g. "../../bud/view/client").
+const Path = "bud/view/client.ts"
+
+//go:embed client.gotext
+var template string
+
+var generator = gotemplate.MustParse("framework/view/client/client.gotext", template)
+
+// Generate the TypeScript client from state
+func Generate(state *State) ([]byte, error) {
+	return generator.Generate(state)
+}
+
+// New TypeScript client generator
+func New(injector *di.Injector, module *gomod.Module, parser *parser.Parser) *Generator {
+	return &Generator{injector, module, parser}
+}
+
+// Generator for bud/view/client.ts
+type Generator struct {
+	injector *di.Injector
+	module   *gomod.Module
+	parser   *parser.Parser
+}
+
+func (g *Generator) GenerateFile(fsys genfs.FS, file *genfs.File) error {
+	state, err := Load(fsys, g.injector, g.module, g.parser)
+	if err != nil {
+		return fmt.Errorf("client: unable to load. %w", err)
+	}
+	code, err := Generate(state)
+	if err != nil {
+		return err
+	}
+	file.Data = code
+	return nil
+}
@@ -0,0 +1,109 @@
+package client_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/livebud/bud/internal/is"
+	"github.com/livebud/bud/internal/testcli"
+	"github.com/livebud/bud/internal/testdir"
+	"github.com/livebud/bud/internal/versions"
+)
+
+const postsController = `
+	package posts
+	import "context"
+	type Controller struct {}
+	type Post struct {
+		ID    int      ` + "`" + `json:"id"` + "`" + `
+		Title string   ` + "`" + `json:"title" validate:"required"` + "`" + `
+		Tags  []string ` + "`" + `json:"tags"` + "`" + `
+	}
+	// Index lists posts
+	func (c *Controller) Index(ctx context.Context, page *int) ([]*Post, error) {
+		return []*Post{{ID: 1, Title: "hello"}}, nil
+	}
+	// Show a post
+	func (c *Controller) Show(id int) (*Post, error) {
+		return &Post{ID: id, Title: "hello"}, nil
+	}
+	// Create a post
+	func (c *Controller) Create(in *Post) (*Post, error) {
+		return in, nil
+	}
+	// Delete a post
+	func (c *Controller) Delete(id int) error {
+		return nil
+	}
+`
+
+func TestNoControllers(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	td := testdir.New(dir)
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(dir)
+	_, err := cli.Run(ctx, "build")
+	is.NoErr(err)
+	is.NoErr(td.NotExists("bud/view/client.ts"))
+}
+
+func TestGenerateClient(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	td := testdir.New(dir)
+	td.Files["controller/posts/controller.go"] = postsController
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(dir)
+	_, err := cli.Run(ctx, "generate")
+	is.NoErr(err)
+	data, err := os.ReadFile(filepath.Join(dir, "bud/view/client.ts"))
+	is.NoErr(err)
+	code := string(data)
+	is.In(code, "export interface Post {\n  id: number\n  tags: string[]\n  title: string\n}")
+	is.In(code, "export interface PostsIndexInput {\n  page?: number | null\n}")
+	is.In(code, "export type PostsIndexOutput = Post[]")
+	is.In(code, "export interface PostsIndexProps {\n  posts: Post[]\n  errors?: Record<string, string[]>\n}")
+	is.In(code, "// Show a post\nexport function postsShow(input: PostsShowInput, init?: RequestInit): Promise<PostsShowOutput> {")
+	is.In(code, `return request("GET", "/posts/:id<int>", input, init)`)
+	is.In(code, "export interface PostsCreateInput {\n  id?: number\n  tags?: string[]\n  title: string\n}")
+	is.In(code, `return request("POST", "/posts", input, init)`)
+	is.In(code, "export type PostsDeleteOutput = void")
+}
+
+func TestImportClientFromView(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	td := testdir.New(dir)
+	td.Files["controller/posts/controller.go"] = postsController
+	td.Files["view/posts/show.svelte"] = `
+		<script>
+			import { postsShow } from "../../bud/view/client"
+			export let post = {}
+		</script>
+		<h1>{post.title}</h1>
+		<p>{typeof postsShow}</p>
+	`
+	td.NodeModules["svelte"] = versions.Svelte
+	td.NodeModules["livebud"] = "*"
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(dir)
+	app, err := cli.Start(ctx, "run")
+	is.NoErr(err)
+	defer app.Close()
+	res, err := app.Get("/posts/1")
+	is.NoErr(err)
+	is.Equal(res.Status(), 200)
+	is.In(res.Body().String(), "<h1>hello</h1>")
+	is.In(res.Body().String(), "<p>function</p>")
+	res, err = app.Get("/bud/view/posts/_show.svelte.js")
+	is.NoErr(err)
+	is.Equal(res.Status(), 200)
+	is.In(res.Body().String(), "/posts/:id")
+	is.NoErr(app.Close())
+}
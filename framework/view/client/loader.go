@@ -0,0 +1,263 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/livebud/bud/framework/controller"
+	"github.com/livebud/bud/framework/openapi"
+	"github.com/livebud/bud/framework/openapi/document"
+	"github.com/livebud/bud/internal/bail"
+	"github.com/livebud/bud/package/di"
+	"github.com/livebud/bud/package/gomod"
+	"github.com/livebud/bud/package/parser"
+	"github.com/matthewmueller/gotext"
+)
+
+// Load the client state from the controllers and their OpenAPI document
+func Load(fsys fs.FS, injector *di.Injector, module *gomod.Module, parser *parser.Parser) (*State, error) {
+	state, err := controller.Load(fsys, injector, module, parser)
+	if err != nil {
+		return nil, err
+	}
+	// Returns fs.ErrNotExist when there aren't any actions to describe
+	data, err := fs.ReadFile(fsys, openapi.Path)
+	if err != nil {
+		return nil, err
+	}
+	doc := new(document.Document)
+	if err := json.Unmarshal(data, doc); err != nil {
+		return nil, fmt.Errorf("client: unable to parse %s. %w", openapi.Path, err)
+	}
+	loader := &loader{
+		document:   doc,
+		operations: map[string]*document.Operation{},
+	}
+	return loader.Load(state)
+}
+
+type loader struct {
+	bail.Struct
+	document   *document.Document
+	operations map[string]*document.Operation // operation id => operation
+}
+
+func (l *loader) Load(state *controller.State) (client *State, err error) {
+	defer l.Recover2(&err, "client: unable to load state")
+	for _, item := range l.document.Paths {
+		for _, operation := range *item {
+			l.operations[operation.OperationID] = operation
+		}
+	}
+	client = new(State)
+	client.Interfaces = l.loadInterfaces()
+	client.Actions = l.loadController(state.Controller)
+	if len(client.Actions) == 0 {
+		return nil, fs.ErrNotExist
+	}
+	return client, nil
+}
+
+// Load an interface for each of the named Go structs
+func (l *loader) loadInterfaces() (interfaces []*Interface) {
+	if l.document.Components == nil {
+		return nil
+	}
+	for name, schema := range l.document.Components.Schemas {
+		// Errors are thrown as a ResponseError instead
+		if name == "Error" {
+			continue
+		}
+		interfaces = append(interfaces, &Interface{
+			Name: typeName(name),
+			// Go always encodes the fields, so they're never optional
+			Fields: l.loadFields(schema, false),
+		})
+	}
+	sort.Slice(interfaces, func(i, j int) bool {
+		return interfaces[i].Name < interfaces[j].Name
+	})
+	return interfaces
+}
+
+func (l *loader) loadController(controller *controller.Controller) (actions []*Action) {
+	for _, action := range controller.Actions {
+		// Handler funcs write their own responses, so they can't be typed
+		if action.HandlerFunc {
+			continue
+		}
+		// Streams and WebSockets aren't read with fetch
+		if action.Stream || action.WebSocket {
+			continue
+		}
+		actions = append(actions, l.loadAction(action))
+	}
+	for _, sub := range controller.Controllers {
+		actions = append(actions, l.loadController(sub)...)
+	}
+	return actions
+}
+
+func (l *loader) loadAction(action *controller.Action) *Action {
+	id := document.OperationID(action)
+	operation, ok := l.operations[id]
+	if !ok {
+		l.Bail(fmt.Errorf("client: unable to find the %q operation", id))
+	}
+	out := new(Action)
+	out.Name = id
+	out.Pascal = gotext.Pascal(id)
+	out.Summary = operation.Summary
+	out.Method = strings.ToUpper(action.Method)
+	out.Route = action.Route
+	out.Input = l.loadInput(operation)
+	out.Output = l.loadOutput(operation)
+	out.Props = l.loadProps(action, out.Output)
+	return out
+}
+
+// Load the path parameters, query parameters and body into a single input
+func (l *loader) loadInput(operation *document.Operation) (fields []*Field) {
+	for _, param := range operation.Parameters {
+		fields = append(fields, &Field{
+			Name:     fieldName(param.Name),
+			Type:     l.loadType(param.Schema),
+			Optional: !param.Required,
+		})
+	}
+	if operation.RequestBody == nil {
+		return fields
+	}
+	media, ok := operation.RequestBody.Content["application/json"]
+	if !ok {
+		return fields
+	}
+	return append(fields, l.loadFields(media.Schema, true)...)
+}
+
+func (l *loader) loadOutput(operation *document.Operation) string {
+	response, ok := operation.Responses["200"]
+	if !ok {
+		return "void"
+	}
+	media, ok := response.Content["application/json"]
+	if !ok {
+		return "unknown"
+	}
+	return l.loadType(media.Schema)
+}
+
+// Props are passed into the view under the action's props key
+func (l *loader) loadProps(action *controller.Action, output string) (fields []*Field) {
+	if action.PropsKey != "" && output != "void" {
+		fields = append(fields, &Field{
+			Name: fieldName(action.PropsKey),
+			Type: output,
+		})
+	}
+	// Validation errors are passed back into the view
+	return append(fields, &Field{
+		Name:     "errors",
+		Type:     "Record<string, string[]>",
+		Optional: true,
+	})
+}
+
+// Load the fields of an object schema. Required fields are only enforced when
+// strict is true.
+func (l *loader) loadFields(schema *document.Schema, strict bool) (fields []*Field) {
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fields = append(fields, &Field{
+			Name:     fieldName(name),
+			Type:     l.loadType(schema.Properties[name]),
+			Optional: strict && !contains(schema.Required, name),
+		})
+	}
+	return fields
+}
+
+// Load the TypeScript type of a schema
+func (l *loader) loadType(schema *document.Schema) string {
+	var out string
+	switch {
+	case schema.Ref != "":
+		out = typeName(strings.TrimPrefix(schema.Ref, "#/components/schemas/"))
+	case schema.Type == "string" && schema.Format == "binary":
+		out = "Blob"
+	case schema.Type == "string":
+		out = "string"
+	case schema.Type == "integer", schema.Type == "number":
+		out = "number"
+	case schema.Type == "boolean":
+		out = "boolean"
+	case schema.Type == "array":
+		out = l.loadType(schema.Items)
+		if strings.Contains(out, " ") {
+			out = "(" + out + ")"
+		}
+		out += "[]"
+	case schema.Type == "object" && schema.AdditionalProperties != nil:
+		out = "Record<string, " + l.loadType(schema.AdditionalProperties) + ">"
+	case schema.Type == "object" && len(schema.Properties) > 0:
+		fields := l.loadFields(schema, false)
+		properties := make([]string, len(fields))
+		for i, field := range fields {
+			properties[i] = field.Name + ": " + field.Type
+		}
+		out = "{ " + strings.Join(properties, "; ") + " }"
+	case schema.Type == "object":
+		out = "Record<string, unknown>"
+	default:
+		out = "unknown"
+	}
+	if schema.Nullable {
+		out += " | null"
+	}
+	return out
+}
+
+// Types used by the client that would be shadowed by a Go struct's interface
+var reserved = map[string]bool{
+	"Blob":        true,
+	"Error":       true,
+	"Promise":     true,
+	"Record":      true,
+	"RequestInit": true,
+}
+
+// Rename types that would clash with the client's types
+func typeName(name string) string {
+	if reserved[name] {
+		return name + "Type"
+	}
+	return name
+}
+
+var reIdentifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// Quote the field name when it's not a valid identifier
+func fieldName(name string) string {
+	if reIdentifier.MatchString(name) {
+		return name
+	}
+	return strconv.Quote(name)
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,32 @@
+package client
+
+// State for generating the TypeScript client
+type State struct {
+	Interfaces []*Interface
+	Actions    []*Action
+}
+
+// Interface is a TypeScript interface
+type Interface struct {
+	Name   string
+	Fields []*Field
+}
+
+// Field of a TypeScript interface
+type Field struct {
+	Name     string // Quoted when it's not a valid identifier
+	Type     string
+	Optional bool
+}
+
+// Action is a typed fetch function for a controller action
+type Action struct {
+	Name    string // Camel-cased function name (e.g. postsShow)
+	Pascal  string // Prefix of the action's types (e.g. PostsShow)
+	Summary string
+	Method  string // Uppercase HTTP method (e.g. GET)
+	Route   string
+	Input   []*Field
+	Output  string // TypeScript type of the JSON response
+	Props   []*Field
+}
@@ -53,9 +53,19 @@ func (l *loader) Load() (state *State, err error) {
 	views, err := entrypoint.List(l.fsys, "view")
 	if err != nil {
 		return nil, err
-	} else if len(views) == 0 {
+	}
+	// Error pages can be rendered without any other views
+	errorViews, err := entrypoint.ListErrors(l.fsys, "view")
+	if err != nil {
+		return nil, err
+	} else if len(views) == 0 && len(errorViews) == 0 {
 		return nil, fs.ErrNotExist
 	}
+	for _, errorView := range errorViews {
+		state.Errors = append(state.Errors, errorView.Route)
+	}
+	// Show the error stacks in development
+	state.Stacks = !l.flag.Embed
 	// Load the embeds
 	if l.flag.Embed {
 		// Add SSR
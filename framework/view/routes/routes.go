@@ -0,0 +1,52 @@
+package routes
+
+import (
+	_ "embed"
+	"fmt"
+
+	"github.com/livebud/bud/framework/controller/routes"
+	"github.com/livebud/bud/internal/gotemplate"
+	"github.com/livebud/bud/package/di"
+	"github.com/livebud/bud/package/genfs"
+	"github.com/livebud/bud/package/gomod"
+	"github.com/livebud/bud/package/parser"
+)
+
+// Path to the generated path builders for views. Views import it from their
+// relative path (e.g. "../../bud/view/routes").
+const Path = "bud/view/routes.ts"
+
+//go:embed routes.gotext
+var template string
+
+var generator = gotemplate.MustParse("framework/view/routes/routes.gotext", template)
+
+// Generate the TypeScript path builders from state
+func Generate(state *routes.State) ([]byte, error) {
+	return generator.Generate(state)
+}
+
+// New TypeScript path builder generator
+func New(injector *di.Injector, module *gomod.Module, parser *parser.Parser) *Generator {
+	return &Generator{injector, module, parser}
+}
+
+// Generator for bud/view/routes.ts
+type Generator struct {
+	injector *di.Injector
+	module   *gomod.Module
+	parser   *parser.Parser
+}
+
+func (g *Generator) GenerateFile(fsys genfs.FS, file *genfs.File) error {
+	state, err := routes.Load(fsys, g.injector, g.module, g.parser)
+	if err != nil {
+		return fmt.Errorf("routes: unable to load. %w", err)
+	}
+	code, err := Generate(state)
+	if err != nil {
+		return err
+	}
+	file.Data = code
+	return nil
+}
@@ -9,20 +9,38 @@ type View = {
   client: string
 }
 
+type Writer = {
+  writeHead(status: number, headers: Record<string, string>): void
+  write(chunk: string): void
+}
+
 export function createView(view: View) {
-  return function ({ props, context }) {
+  // Create the element tree and the tags to inject into the head
+  function prepare({ props, context }) {
     let component = React.createElement(view.page, props, [])
     for (let frame of view.frames) {
       component = React.createElement(frame, props, component)
     }
     let component2 = React.createElement("div", { id: "bud_target" }, component)
     const layout = view.layout || defaultLayout
-    let component3 = React.createElement(layout, props, component2)
-    let html = ReactSSR.renderToString(component3)
+    // Layouts receive the CSRF token to render into forms
+    const csrf = (context && context.csrf) || ""
+    let component3 = React.createElement(layout, { ...props, csrf }, component2)
     let inject = ""
+    if (csrf) {
+      inject += `<meta name="csrf-token" content="${escapeAttribute(csrf)}">`
+    }
     const hydrate = JSON.stringify(props)
     inject += `<script id="bud_props" type="text/template" defer>${hydrate}</script>`
     inject += `<script type="module" src="${view.client}" defer></script>`
+    return { element: component3, inject }
+  }
+  function render({ props, context }) {
+    if (context && context.error) {
+      return renderError(view, context.error)
+    }
+    const { element, inject } = prepare({ props, context })
+    let html = ReactSSR.renderToString(element)
     html = html.replace("</head>", inject + `</head>`)
     return {
       status: 200,
@@ -32,6 +50,85 @@ export function createView(view: View) {
       body: html,
     }
   }
+  // Stream the view with React's stream API. The head is written as soon as
+  // React renders the shell.
+  async function stream({ props, context }, res: Writer) {
+    if (!canStream() || (context && context.error)) {
+      const { status, headers, body } = render({ props, context })
+      res.writeHead(status, headers)
+      res.write(body)
+      return
+    }
+    const { element, inject } = prepare({ props, context })
+    const readable = await ReactSSR.renderToReadableStream(element)
+    res.writeHead(200, {
+      "Content-Type": "text/html",
+    })
+    const reader = readable.getReader()
+    const decoder = new TextDecoder()
+    // Buffer until the end of the head, so the tags can be injected into it
+    let head = ""
+    let injected = false
+    while (true) {
+      const { done, value } = await reader.read()
+      if (done) break
+      const chunk = decoder.decode(value, { stream: true })
+      if (injected) {
+        res.write(chunk)
+        continue
+      }
+      head += chunk
+      const end = head.indexOf("</head>")
+      if (end < 0) continue
+      res.write(head.slice(0, end) + inject + head.slice(end))
+      injected = true
+    }
+    const rest = decoder.decode()
+    res.write(injected ? rest : head + rest)
+  }
+  return { render, stream }
+}
+
+type ErrorProps = {
+  status: number
+  message: string
+  stack?: string // Only in development
+}
+
+// Render the error page within the layout. Error pages aren't hydrated.
+function renderError(view: View, props: ErrorProps) {
+  if (!view.error) {
+    return {
+      status: props.status,
+      headers: {
+        "Content-Type": "text/plain; charset=utf-8",
+      },
+      body: props.message,
+    }
+  }
+  const page = React.createElement(view.error, props)
+  const layout = view.layout || defaultLayout
+  const html = ReactSSR.renderToString(React.createElement(layout, props, page))
+  return {
+    status: props.status,
+    headers: {
+      "Content-Type": "text/html",
+    },
+    body: html,
+  }
+}
+
+// React's stream API needs web streams, which aren't available in every VM
+function canStream() {
+  return (
+    typeof ReactSSR.renderToReadableStream === "function" &&
+    typeof ReadableStream !== "undefined" &&
+    typeof TextDecoder !== "undefined"
+  )
+}
+
+function escapeAttribute(value: string) {
+  return value.replace(/&/g, "&amp;").replace(/"/g, "&quot;").replace(/</g, "&lt;")
 }
 
 function defaultLayout(props) {
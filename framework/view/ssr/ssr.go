@@ -133,8 +133,13 @@ func ssrPlugin(fsys fs.FS, dir string) esbuild.Plugin {
 				if err != nil {
 					return result, err
 				}
+				errors, err := entrypoint.ListErrors(fsys, "view")
+				if err != nil {
+					return result, err
+				}
 				code, err := ssrGenerator.Generate(map[string]interface{}{
-					"Views": views,
+					"Views":  views,
+					"Errors": errors,
 				})
 				if err != nil {
 					return result, err
@@ -11,7 +11,50 @@ type Response = {
   body: string
 }
 
+// Writer streams the response. The head is written before the body.
+export type Writer = {
+  writeHead(status: number, headers: Record<string, string>): void
+  write(chunk: string): void
+}
+
+// Error page for the routes below it
+type ErrorView = {
+  route: string
+  view: any
+}
+
+// Find the error page closest to the route. Parameters in the error page's
+// route match any segment.
+export function findError(errors: ErrorView[], route: string): any {
+  const segments = split(route)
+  let found = null
+  let depth = -1
+  for (const error of errors) {
+    const prefix = split(error.route)
+    if (prefix.length <= depth || prefix.length > segments.length) {
+      continue
+    }
+    const matches = prefix.every((segment, i) => {
+      return segment === segments[i] || segment.startsWith(":")
+    })
+    if (matches) {
+      found = error.view
+      depth = prefix.length
+    }
+  }
+  return found
+}
+
+function split(route: string): string[] {
+  return route.split("/").filter(Boolean)
+}
+
 export function renderHTML(input: Input): Response {
+  // Errors without an error page are rendered as text
+  const error = input.context && input.context.error
+  if (error && !input.view) {
+    return errorText(error)
+  }
   // Handle the missing view
   if (!input.view) {
     return {
@@ -20,7 +63,34 @@ export function renderHTML(input: Input): Response {
       body: fallback(new Error('Missing page "' + input.route + '"')),
     }
   }
-  return input.view({ props: input.props, context: input.context })
+  return input.view.render({ props: input.props, context: input.context })
+}
+
+export function streamHTML(input: Input, res: Writer): void | Promise<void> {
+  const error = input.context && input.context.error
+  if (error && !input.view) {
+    const { status, headers, body } = errorText(error)
+    res.writeHead(status, headers)
+    res.write(body)
+    return
+  }
+  // Handle the missing view
+  if (!input.view) {
+    res.writeHead(404, {})
+    res.write(fallback(new Error('Missing page "' + input.route + '"')))
+    return
+  }
+  return input.view.stream({ props: input.props, context: input.context }, res)
+}
+
+function errorText(error: { status: number; message: string }): Response {
+  return {
+    status: error.status,
+    headers: {
+      "Content-Type": "text/plain; charset=utf-8",
+    },
+    body: error.message,
+  }
 }
 
 function fallback(err: Error) {
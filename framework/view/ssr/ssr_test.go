@@ -65,6 +65,244 @@ func TestSvelteHello(t *testing.T) {
 	is.True(strings.Contains(res.Body, `<h1>hi world</h1>`))
 }
 
+func TestSvelteStream(t *testing.T) {
+	is := is.New(t)
+	log := testlog.New()
+	ctx := context.Background()
+	dir := t.TempDir()
+	td := testdir.New(dir)
+	td.Files["view/index.svelte"] = `
+		<script>
+			export let name = ""
+		</script>
+		<svelte:head><title>{name}</title></svelte:head>
+		<h1>hi {name}</h1>
+		<style>h1 { color: blue }</style>
+	`
+	td.NodeModules["svelte"] = versions.Svelte
+	is.NoErr(td.Write(ctx))
+	vm, err := v8.Load()
+	is.NoErr(err)
+	svelteCompiler, err := svelte.Load(vm)
+	is.NoErr(err)
+	transformer, err := transformrt.Default(log, svelteCompiler)
+	is.NoErr(err)
+	module, err := gomod.Find(dir)
+	is.NoErr(err)
+	gfs := genfs.New(dag.Discard, module, log)
+	gfs.FileGenerator("bud/view/_ssr.js", ssr.New(module, transformer))
+	code, err := fs.ReadFile(gfs, "bud/view/_ssr.js")
+	is.NoErr(err)
+	is.NoErr(vm.Script("_ssr.js", string(code)))
+	chunks := new(chunkWriter)
+	is.NoErr(vm.Stream(ctx, "render.js", `bud.stream("/", {"name": "world"}, {})`, chunks))
+	// The head is written first
+	is.True(len(chunks.chunks) >= 3)
+	var res ssr.Response
+	is.NoErr(json.Unmarshal([]byte(chunks.chunks[0]), &res))
+	is.Equal(res.Status, 200)
+	is.Equal(res.Headers["Content-Type"], "text/html")
+	// Then the layout's head, before the page has rendered
+	head := chunks.chunks[1]
+	is.In(head, `<script id="bud_props" type="text/template" defer>{"name":"world"}</script>`)
+	is.In(head, `<link rel="modulepreload" href="/bud/view/_index.svelte.js">`)
+	is.True(!strings.Contains(head, "hi world"))
+	// Then the page
+	body := strings.Join(chunks.chunks[2:], "")
+	is.In(body, `<title>world</title>`)
+	is.In(body, `color:blue`)
+	is.In(body, `<div id="bud_target"><h1 class="`)
+	is.In(body, `hi world</h1>`)
+	// Missing views respond with a 404
+	chunks = new(chunkWriter)
+	is.NoErr(vm.Stream(ctx, "render.js", `bud.stream("/missing", {}, {})`, chunks))
+	is.NoErr(json.Unmarshal([]byte(chunks.chunks[0]), &res))
+	is.Equal(res.Status, 404)
+}
+
+func TestSvelteErrorPage(t *testing.T) {
+	is := is.New(t)
+	log := testlog.New()
+	ctx := context.Background()
+	dir := t.TempDir()
+	td := testdir.New(dir)
+	td.Files["view/index.svelte"] = `<h1>hi world</h1>`
+	td.Files["view/Error.svelte"] = `
+		<script>
+			export let status = 500
+			export let message = ""
+		</script>
+		<h1>{status}: {message}</h1>
+	`
+	td.Files["view/posts/comments/Error.svelte"] = `
+		<script>
+			export let status = 500
+			export let message = ""
+			export let stack = ""
+		</script>
+		<h1>comments {status}: {message}</h1>
+		<pre>{stack}</pre>
+	`
+	td.NodeModules["svelte"] = versions.Svelte
+	is.NoErr(td.Write(ctx))
+	vm, err := v8.Load()
+	is.NoErr(err)
+	svelteCompiler, err := svelte.Load(vm)
+	is.NoErr(err)
+	transformer, err := transformrt.Default(log, svelteCompiler)
+	is.NoErr(err)
+	module, err := gomod.Find(dir)
+	is.NoErr(err)
+	gfs := genfs.New(dag.Discard, module, log)
+	gfs.FileGenerator("bud/view/_ssr.js", ssr.New(module, transformer))
+	code, err := fs.ReadFile(gfs, "bud/view/_ssr.js")
+	is.NoErr(err)
+	is.NoErr(vm.Script("_ssr.js", string(code)))
+	// Errors render the closest error page
+	result, err := vm.Eval("render.js", `bud.render("/posts/10/comments/3", {}, {"error": {"status": 404, "message": "not found", "stack": "at show"}})`)
+	is.NoErr(err)
+	var res ssr.Response
+	is.NoErr(json.Unmarshal([]byte(result), &res))
+	is.Equal(res.Status, 404)
+	is.In(res.Body, "<h1>comments 404: not found</h1>")
+	is.In(res.Body, "<pre>at show</pre>")
+	// Routes without a closer error page render the root error page
+	chunks := new(chunkWriter)
+	is.NoErr(vm.Stream(ctx, "render.js", `bud.stream("/posts/10", {}, {"error": {"status": 500, "message": "boom"}})`, chunks))
+	is.NoErr(json.Unmarshal([]byte(chunks.chunks[0]), &res))
+	is.Equal(res.Status, 500)
+	is.In(strings.Join(chunks.chunks[1:], ""), "<h1>500: boom</h1>")
+}
+
+func TestSvelteErrorLayout(t *testing.T) {
+	is := is.New(t)
+	log := testlog.New()
+	ctx := context.Background()
+	dir := t.TempDir()
+	td := testdir.New(dir)
+	td.Files["view/Layout.svelte"] = `
+		<script>
+			import { getContext } from "svelte"
+			const csrf = getContext("csrf")
+		</script>
+		<html>
+			<head><slot name="head" /></head>
+			<body><main data-csrf={csrf}><slot /></main></body>
+		</html>
+	`
+	td.Files["view/index.svelte"] = `<h1>hi world</h1>`
+	td.Files["view/Error.svelte"] = `
+		<script>
+			import { getContext } from "svelte"
+			export let status = 500
+			export let message = ""
+			const csrf = getContext("csrf")
+		</script>
+		<h1>{status}: {message}</h1>
+		<form><input type="hidden" name="_csrf" value={csrf}></form>
+	`
+	td.NodeModules["svelte"] = versions.Svelte
+	is.NoErr(td.Write(ctx))
+	vm, err := v8.Load()
+	is.NoErr(err)
+	svelteCompiler, err := svelte.Load(vm)
+	is.NoErr(err)
+	transformer, err := transformrt.Default(log, svelteCompiler)
+	is.NoErr(err)
+	module, err := gomod.Find(dir)
+	is.NoErr(err)
+	gfs := genfs.New(dag.Discard, module, log)
+	gfs.FileGenerator("bud/view/_ssr.js", ssr.New(module, transformer))
+	code, err := fs.ReadFile(gfs, "bud/view/_ssr.js")
+	is.NoErr(err)
+	is.NoErr(vm.Script("_ssr.js", string(code)))
+	// The error page renders in the layout's slot with the same context
+	result, err := vm.Eval("render.js", `bud.render("/", {}, {"csrf": "abc", "error": {"status": 404, "message": "not found"}})`)
+	is.NoErr(err)
+	var res ssr.Response
+	is.NoErr(json.Unmarshal([]byte(result), &res))
+	is.Equal(res.Status, 404)
+	is.In(res.Body, `<main data-csrf="abc"><h1>404: not found</h1>`)
+	is.In(res.Body, `<input type="hidden" name="_csrf" value="abc">`)
+	// Streams render the error page the same way
+	chunks := new(chunkWriter)
+	is.NoErr(vm.Stream(ctx, "render.js", `bud.stream("/", {}, {"csrf": "abc", "error": {"status": 500, "message": "boom"}})`, chunks))
+	is.NoErr(json.Unmarshal([]byte(chunks.chunks[0]), &res))
+	is.Equal(res.Status, 500)
+	is.In(strings.Join(chunks.chunks[1:], ""), `<main data-csrf="abc"><h1>500: boom</h1>`)
+}
+
+func TestSvelteFrames(t *testing.T) {
+	is := is.New(t)
+	log := testlog.New()
+	ctx := context.Background()
+	dir := t.TempDir()
+	td := testdir.New(dir)
+	td.Files["view/Frame.svelte"] = `
+		<script>
+			export let name = ""
+		</script>
+		<svelte:head><title>{name}</title></svelte:head>
+		<main class="outer"><slot /></main>
+		<style>main { color: red }</style>
+	`
+	td.Files["view/admin/Frame.svelte"] = `
+		<script>
+			export let name = ""
+		</script>
+		<nav>sidebar for {name}</nav>
+		<section class="inner"><slot /></section>
+	`
+	td.Files["view/admin/index.svelte"] = `
+		<script>
+			export let name = ""
+		</script>
+		<h1>hi {name}</h1>
+	`
+	td.NodeModules["svelte"] = versions.Svelte
+	is.NoErr(td.Write(ctx))
+	vm, err := v8.Load()
+	is.NoErr(err)
+	svelteCompiler, err := svelte.Load(vm)
+	is.NoErr(err)
+	transformer, err := transformrt.Default(log, svelteCompiler)
+	is.NoErr(err)
+	module, err := gomod.Find(dir)
+	is.NoErr(err)
+	gfs := genfs.New(dag.Discard, module, log)
+	gfs.FileGenerator("bud/view/_ssr.js", ssr.New(module, transformer))
+	code, err := fs.ReadFile(gfs, "bud/view/_ssr.js")
+	is.NoErr(err)
+	res, err := render(vm, string(code), "/admin", map[string]interface{}{"name": "world"})
+	is.NoErr(err)
+	is.Equal(res.Status, 200)
+	// Frames nest around the page with the same props
+	is.In(res.Body, `<title>world</title>`)
+	is.In(res.Body, `color:red`)
+	is.In(res.Body, `<nav>sidebar for world</nav>`)
+	outer := strings.Index(res.Body, `class="outer`)
+	inner := strings.Index(res.Body, `class="inner`)
+	page := strings.Index(res.Body, `<h1>hi world</h1>`)
+	is.True(outer >= 0 && outer < inner && inner < page)
+	// Streamed pages are framed too
+	is.NoErr(vm.Script("_ssr.js", string(code)))
+	chunks := new(chunkWriter)
+	is.NoErr(vm.Stream(ctx, "render.js", `bud.stream("/admin", {"name": "world"}, {})`, chunks))
+	body := strings.Join(chunks.chunks[1:], "")
+	is.In(body, `<nav>sidebar for world</nav>`)
+	is.In(body, `<h1>hi world</h1>`)
+}
+
+// Records each chunk that's written
+type chunkWriter struct {
+	chunks []string
+}
+
+func (c *chunkWriter) Write(p []byte) (int, error) {
+	c.chunks = append(c.chunks, string(p))
+	return len(p), nil
+}
+
 func TestSvelteAwait(t *testing.T) {
 	is := is.New(t)
 	log := testlog.New()
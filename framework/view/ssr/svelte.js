@@ -284,18 +284,26 @@ var require_jsesc = __commonJS({
 
 // svelte.ts
 var import_jsesc = __toESM(require_jsesc());
+var headMarker = "<!--bud:head-->";
+var bodyMarker = "<!--bud:body-->";
 function createView(view) {
   view.layout = view.layout || defaultLayout;
-  return function({ props, context }) {
-    const page = view.page.render(props);
+  function render({ props, context }) {
+    if (context && context.error) {
+      return renderError(view, context.error, context.csrf || "");
+    }
+    const csrf = context && context.csrf || "";
+    const svelteContext = /* @__PURE__ */ new Map([["csrf", csrf]]);
+    const page = renderPage(view, props, svelteContext);
     let css = page.css.code;
     let html = page.html;
     let head = page.head;
     const hydrate = (0, import_jsesc.default)(props, { isScriptContext: true, json: true });
-    const layout = view.layout.render(props, {
+    const slots = {
       head: function() {
         return `
           ${head}
+          ${csrf ? `<meta name="csrf-token" content="${escapeAttribute(csrf)}">` : ""}
           <style>#bud{}${css}</style>
           <script id="bud_props" type="text/template" defer>${hydrate}<\/script>
           <script type="module" src="${view.client}" defer><\/script>
@@ -304,6 +312,11 @@ function createView(view) {
       default: function() {
         return '<div id="bud_target">' + html + "</div>";
       }
+    };
+    const layout = view.layout.render(props, {
+      ...slots,
+      $$slots: slots,
+      context: svelteContext
     });
     html = layout.html.replace("#bud{}", layout.css.code);
     return {
@@ -313,6 +326,119 @@ function createView(view) {
       },
       body: html
     };
+  }
+  function stream({ props, context }, res) {
+    if (context && context.error) {
+      return writeResponse(res, renderError(view, context.error, context.csrf || ""));
+    }
+    const csrf = context && context.csrf || "";
+    const svelteContext = /* @__PURE__ */ new Map([["csrf", csrf]]);
+    const hydrate = (0, import_jsesc.default)(props, { isScriptContext: true, json: true });
+    const slots = {
+      head: function() {
+        return `
+          ${csrf ? `<meta name="csrf-token" content="${escapeAttribute(csrf)}">` : ""}
+          <style>#bud{}</style>
+          <script id="bud_props" type="text/template" defer>${hydrate}<\/script>
+          <link rel="modulepreload" href="${view.client}">
+          <script type="module" src="${view.client}" defer><\/script>
+          ${headMarker}
+        `;
+      },
+      default: function() {
+        return bodyMarker;
+      }
+    };
+    const layout = view.layout.render(props, {
+      ...slots,
+      $$slots: slots,
+      context: svelteContext
+    });
+    const html = layout.html.replace("#bud{}", layout.css.code);
+    const headAt = html.indexOf(headMarker);
+    const bodyAt = html.indexOf(bodyMarker);
+    if (headAt < 0 || bodyAt < headAt) {
+      return writeResponse(res, render({ props, context }));
+    }
+    res.writeHead(200, {
+      "Content-Type": "text/html"
+    });
+    res.write(html.slice(0, headAt));
+    const page = renderPage(view, props, svelteContext);
+    res.write(`
+      ${page.head}
+      <style>${page.css.code}</style>
+      ${html.slice(headAt + headMarker.length, bodyAt)}
+      <div id="bud_target">${page.html}</div>
+      ${html.slice(bodyAt + bodyMarker.length)}
+    `);
+  }
+  return { render, stream };
+}
+function writeResponse(res, response) {
+  res.writeHead(response.status, response.headers);
+  res.write(response.body);
+}
+function renderPage(view, props, context) {
+  const page = view.page.render(props, { context });
+  let html = page.html;
+  let head = page.head;
+  let css = page.css.code;
+  for (let i = view.frames.length - 1; i >= 0; i--) {
+    const inner = html;
+    const slots = {
+      default: function() {
+        return inner;
+      }
+    };
+    const frame = view.frames[i].render(props, {
+      ...slots,
+      $$slots: slots,
+      context
+    });
+    html = frame.html;
+    head = frame.head + head;
+    css = frame.css.code + css;
+  }
+  return { html, head, css: { code: css } };
+}
+function escapeAttribute(value) {
+  return value.replace(/&/g, "&amp;").replace(/"/g, "&quot;").replace(/</g, "&lt;");
+}
+function renderError(view, props, csrf) {
+  if (!view.error) {
+    return {
+      status: props.status,
+      headers: {
+        "Content-Type": "text/plain; charset=utf-8"
+      },
+      body: props.message
+    };
+  }
+  const svelteContext = /* @__PURE__ */ new Map([["csrf", csrf]]);
+  const page = view.error.render(props, { context: svelteContext });
+  const slots = {
+    head: function() {
+      return `
+        ${page.head}
+        <style>#bud{}${page.css.code}</style>
+      `;
+    },
+    default: function() {
+      return page.html;
+    }
+  };
+  const layout = view.layout.render(props, {
+    ...slots,
+    $$slots: slots,
+    context: svelteContext
+  });
+  return {
+    status: props.status,
+    headers: {
+      "Content-Type": "text/html"
+    },
+    body: layout.html.replace("#bud{}", layout.css.code)
   };
 }
 var defaultLayout = {
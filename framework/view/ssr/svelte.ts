@@ -8,24 +8,38 @@ type View = {
   client: string
 }
 
+type Writer = {
+  writeHead(status: number, headers: Record<string, string>): void
+  write(chunk: string): void
+}
+
+// Markers for where the slots go in the layout when streaming
+const headMarker = "<!--bud:head-->"
+const bodyMarker = "<!--bud:body-->"
+
 // TODO:
 // - Test custom layouts
-// - Support frames
 // - Support default errors
-// - Support custom errors
 export function createView(view: View) {
   view.layout = view.layout || defaultLayout
-  return function ({ props, context }) {
-    const page = view.page.render(props)
+  function render({ props, context }) {
+    if (context && context.error) {
+      return renderError(view, context.error, context.csrf || "")
+    }
+    // Components can read the CSRF token with getContext("csrf")
+    const csrf = (context && context.csrf) || ""
+    const svelteContext = new Map([["csrf", csrf]])
+    const page = renderPage(view, props, svelteContext)
     let css = page.css.code
     let html = page.html
     let head = page.head
     // Render the layout
     const hydrate = jsesc(props, { isScriptContext: true, json: true })
-    const layout = view.layout.render(props, {
+    const slots = {
       head: function () {
         return `
           ${head}
+          ${csrf ? `<meta name="csrf-token" content="${escapeAttribute(csrf)}">` : ""}
           <style>#bud{}${css}</style>
           <script id="bud_props" type="text/template" defer>${hydrate}</script>
           <script type="module" src="${view.client}" defer></script>
@@ -34,6 +48,11 @@ export function createView(view: View) {
       default: function () {
         return '<div id="bud_target">' + html + "</div>"
       },
+    }
+    const layout = view.layout.render(props, {
+      ...slots,
+      $$slots: slots,
+      context: svelteContext,
     })
     html = layout.html.replace("#bud{}", layout.css.code)
     return {
@@ -44,6 +63,138 @@ export function createView(view: View) {
       body: html,
     }
   }
+  // Stream the layout's head before rendering the page, so the browser can
+  // start loading the client while the page renders
+  function stream({ props, context }, res: Writer) {
+    if (context && context.error) {
+      return writeResponse(res, renderError(view, context.error, context.csrf || ""))
+    }
+    const csrf = (context && context.csrf) || ""
+    const svelteContext = new Map([["csrf", csrf]])
+    const hydrate = jsesc(props, { isScriptContext: true, json: true })
+    // Render the layout around markers that are replaced by the page
+    const slots = {
+      head: function () {
+        return `
+          ${csrf ? `<meta name="csrf-token" content="${escapeAttribute(csrf)}">` : ""}
+          <style>#bud{}</style>
+          <script id="bud_props" type="text/template" defer>${hydrate}</script>
+          <link rel="modulepreload" href="${view.client}">
+          <script type="module" src="${view.client}" defer></script>
+          ${headMarker}
+        `
+      },
+      default: function () {
+        return bodyMarker
+      },
+    }
+    const layout = view.layout.render(props, {
+      ...slots,
+      $$slots: slots,
+      context: svelteContext,
+    })
+    const html = layout.html.replace("#bud{}", layout.css.code)
+    const headAt = html.indexOf(headMarker)
+    const bodyAt = html.indexOf(bodyMarker)
+    // Layouts that don't render both slots are rendered all at once
+    if (headAt < 0 || bodyAt < headAt) {
+      return writeResponse(res, render({ props, context }))
+    }
+    res.writeHead(200, {
+      "Content-Type": "text/html",
+    })
+    res.write(html.slice(0, headAt))
+    const page = renderPage(view, props, svelteContext)
+    res.write(`
+      ${page.head}
+      <style>${page.css.code}</style>
+      ${html.slice(headAt + headMarker.length, bodyAt)}
+      <div id="bud_target">${page.html}</div>
+      ${html.slice(bodyAt + bodyMarker.length)}
+    `)
+  }
+  return { render, stream }
+}
+
+function writeResponse(res: Writer, response: { status: number; headers: Record<string, string>; body: string }) {
+  res.writeHead(response.status, response.headers)
+  res.write(response.body)
+}
+
+// Render the page within its frames. The first frame is on the outside and each
+// frame renders the next one in its default slot. Frames receive the page's
+// props.
+function renderPage(view: View, props: Record<string, any>, context: Map<string, any>) {
+  const page = view.page.render(props, { context })
+  let html = page.html
+  let head = page.head
+  let css = page.css.code
+  for (let i = view.frames.length - 1; i >= 0; i--) {
+    const inner = html
+    const slots = {
+      default: function () {
+        return inner
+      },
+    }
+    const frame = view.frames[i].render(props, {
+      ...slots,
+      $$slots: slots,
+      context,
+    })
+    html = frame.html
+    head = frame.head + head
+    css = frame.css.code + css
+  }
+  return { html, head, css: { code: css } }
+}
+
+function escapeAttribute(value: string) {
+  return value.replace(/&/g, "&amp;").replace(/"/g, "&quot;").replace(/</g, "&lt;")
+}
+
+type ErrorProps = {
+  status: number
+  message: string
+  stack?: string // Only in development
+}
+
+// Render the error page within the layout. Error pages aren't hydrated, but
+// they receive the same slots and context as pages do.
+function renderError(view: View, props: ErrorProps, csrf: string) {
+  if (!view.error) {
+    return {
+      status: props.status,
+      headers: {
+        "Content-Type": "text/plain; charset=utf-8",
+      },
+      body: props.message,
+    }
+  }
+  const svelteContext = new Map([["csrf", csrf]])
+  const page = view.error.render(props, { context: svelteContext })
+  const slots = {
+    head: function () {
+      return `
+        ${page.head}
+        <style>#bud{}${page.css.code}</style>
+      `
+    },
+    default: function () {
+      return page.html
+    },
+  }
+  const layout = view.layout.render(props, {
+    ...slots,
+    $$slots: slots,
+    context: svelteContext,
+  })
+  return {
+    status: props.status,
+    headers: {
+      "Content-Type": "text/html",
+    },
+    body: layout.html.replace("#bud{}", layout.css.code),
+  }
 }
 
 const defaultLayout = {
@@ -9,4 +9,6 @@ type State struct {
 	Imports []*imports.Import
 	Routes  []string
 	Embeds  []*embed.File
+	Stacks  bool     // Add stacks to error pages
+	Errors  []string // Routes of the error pages
 }
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"strings"
 	"testing"
 	"time"
 
@@ -14,6 +15,7 @@ import (
 	"github.com/livebud/bud/internal/testcli"
 	"github.com/livebud/bud/internal/testdir"
 	"github.com/livebud/bud/internal/versions"
+	"golang.org/x/sync/errgroup"
 )
 
 func TestHello(t *testing.T) {
@@ -45,6 +47,8 @@ func TestHello(t *testing.T) {
 		Content-Type: text/html
 	`))
 	is.In(res.Body().String(), "<h1>hello</h1>")
+	// Views are streamed with the client preloaded in the head
+	is.In(res.Body().String(), `<link rel="modulepreload" href="/bud/view/_index.svelte.js">`)
 	is.NoErr(td.Exists("bud/internal/web/view/view.go"))
 	// Change svelte file
 	indexFile := filepath.Join(dir, "view/index.svelte")
@@ -181,6 +185,51 @@ func TestHelloEmbed(t *testing.T) {
 	is.NoErr(app.Close())
 }
 
+func TestParallelRendersEmbed(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	td := testdir.New(dir)
+	td.Files["controller/controller.go"] = `
+		package controller
+		type Controller struct {}
+		func (c *Controller) Show(id int) (postID int) { return id }
+	`
+	td.Files["view/show.svelte"] = `
+		<script>
+			export let postID = 0
+		</script>
+		<h1>post {postID}</h1>
+	`
+	td.NodeModules["svelte"] = versions.Svelte
+	td.NodeModules["livebud"] = "*"
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(dir)
+	app, err := cli.Start(ctx, "run", "--embed")
+	is.NoErr(err)
+	defer app.Close()
+	// Each render gets its own props, even when they run in parallel
+	eg := new(errgroup.Group)
+	for i := 0; i < 20; i++ {
+		id := i
+		eg.Go(func() error {
+			res, err := app.Get(fmt.Sprintf("/%d", id))
+			if err != nil {
+				return err
+			}
+			if res.Status() != 200 {
+				return fmt.Errorf("expected 200, got %d", res.Status())
+			}
+			if !strings.Contains(res.Body().String(), fmt.Sprintf("<h1>post %d</h1>", id)) {
+				return fmt.Errorf("unexpected body %q", res.Body().String())
+			}
+			return nil
+		})
+	}
+	is.NoErr(eg.Wait())
+	is.NoErr(app.Close())
+}
+
 var chunkRe = regexp.MustCompile(`chunk-[A-Za-z0-9]+\.js`)
 
 func findChunk(name, src string) (string, error) {
@@ -487,3 +536,177 @@ func TestSvelteImportFromOtherDir(t *testing.T) {
 	is.In(res.Body().String(), "<h1>The Time</h1>")
 	is.NoErr(app.Close())
 }
+
+func TestCSRFToken(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	td := testdir.New(dir)
+	td.Files["middleware/middleware.go"] = `
+		package middleware
+		import "github.com/livebud/bud/package/middleware"
+		type Stack []middleware.Middleware
+		func New() Stack {
+			return Stack{middleware.CSRF()}
+		}
+	`
+	td.Files["controller/controller.go"] = `
+		package controller
+		type Controller struct {}
+		func (c *Controller) Index() string { return "" }
+		func (c *Controller) Create() {}
+	`
+	td.Files["view/index.svelte"] = `
+		<script>
+			import { getContext } from "svelte"
+			const csrf = getContext("csrf")
+		</script>
+		<form method="post" action="/">
+			<input type="hidden" name="_csrf" value={csrf} />
+		</form>
+	`
+	td.NodeModules["svelte"] = versions.Svelte
+	td.NodeModules["livebud"] = "*"
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(dir)
+	app, err := cli.Start(ctx, "run")
+	is.NoErr(err)
+	defer app.Close()
+	res, err := app.Get("/")
+	is.NoErr(err)
+	is.Equal(res.Status(), 200)
+	cookie := res.Header("Set-Cookie")
+	is.In(cookie, "_csrf=")
+	token := strings.TrimPrefix(strings.Split(cookie, ";")[0], "_csrf=")
+	is.In(res.Body().String(), `<meta name="csrf-token" content="`+token+`">`)
+	is.In(res.Body().String(), `<input type="hidden" name="_csrf" value="`+token+`">`)
+	// Forms without the token are forbidden
+	req, err := app.PostRequest("/", strings.NewReader(""))
+	is.NoErr(err)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Cookie", "_csrf="+token)
+	res, err = app.Do(req)
+	is.NoErr(err)
+	is.Equal(res.Status(), 403)
+	// Forms with the token are allowed
+	req, err = app.PostRequest("/", strings.NewReader("_csrf="+token))
+	is.NoErr(err)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Cookie", "_csrf="+token)
+	res, err = app.Do(req)
+	is.NoErr(err)
+	is.Equal(res.Status(), 302)
+	is.NoErr(app.Close())
+}
+
+func TestErrorPage(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	td := testdir.New(dir)
+	td.Files["controller/controller.go"] = `
+		package controller
+		type Controller struct {}
+		func (c *Controller) Index() string { return "" }
+	`
+	td.Files["controller/posts/controller.go"] = `
+		package posts
+		import "github.com/livebud/bud/framework/controller/controllerrt/response"
+		type Controller struct {}
+		func (c *Controller) Show(id int) (string, error) {
+			return "", response.ErrNotFound
+		}
+	`
+	td.Files["view/index.svelte"] = `<h1>hello</h1>`
+	td.Files["view/Error.svelte"] = `
+		<script>
+			export let status = 500
+			export let message = ""
+			export let stack = ""
+		</script>
+		<h1>{status}: {message}</h1>
+		<pre>{stack}</pre>
+	`
+	td.Files["view/posts/Error.svelte"] = `
+		<script>
+			export let status = 500
+			export let message = ""
+		</script>
+		<h1>post {status}: {message}</h1>
+	`
+	td.NodeModules["svelte"] = versions.Svelte
+	td.NodeModules["livebud"] = "*"
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(dir)
+	app, err := cli.Start(ctx, "run")
+	is.NoErr(err)
+	defer app.Close()
+	// Unknown routes render the root error page
+	res, err := app.Get("/missing")
+	is.NoErr(err)
+	is.NoErr(res.DiffHeaders(`
+		HTTP/1.1 404 Not Found
+		Transfer-Encoding: chunked
+		Content-Type: text/html
+	`))
+	is.In(res.Body().String(), "<h1>404: not found</h1>")
+	is.In(res.Body().String(), "<pre>not found</pre>")
+	// Controllers without a view render the closest error page
+	res, err = app.Get("/posts/10")
+	is.NoErr(err)
+	is.Equal(res.Status(), 404)
+	is.In(res.Body().String(), "<h1>post 404: not found</h1>")
+	// JSON requests keep the JSON error
+	res, err = app.GetJSON("/posts/10")
+	is.NoErr(err)
+	is.Equal(res.Status(), 404)
+	is.Equal(res.Body().String(), `{"error":"not found"}`)
+	res, err = app.GetJSON("/missing")
+	is.NoErr(err)
+	is.Equal(res.Status(), 404)
+	is.Equal(res.Body().String(), `{"error":"not found"}`)
+	is.NoErr(app.Close())
+}
+
+func TestNotFoundWithoutErrorPage(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	td := testdir.New(dir)
+	td.Files["controller/controller.go"] = `
+		package controller
+		type Controller struct {}
+		func (c *Controller) Index() string { return "" }
+	`
+	td.Files["view/index.svelte"] = `<h1>hello</h1>`
+	td.Files["view/posts/Error.svelte"] = `
+		<script>
+			export let status = 500
+			export let message = ""
+		</script>
+		<h1>post {status}: {message}</h1>
+	`
+	td.NodeModules["svelte"] = versions.Svelte
+	td.NodeModules["livebud"] = "*"
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(dir)
+	app, err := cli.Start(ctx, "run")
+	is.NoErr(err)
+	defer app.Close()
+	// Routes without an error page keep the plain 404
+	res, err := app.Get("/missing")
+	is.NoErr(err)
+	is.NoErr(res.Diff(`
+		HTTP/1.1 404 Not Found
+		Content-Type: text/plain; charset=utf-8
+		X-Content-Type-Options: nosniff
+
+		404 page not found
+	`))
+	// Routes below the error page render it
+	res, err = app.Get("/posts/10")
+	is.NoErr(err)
+	is.Equal(res.Status(), 404)
+	is.In(res.Body().String(), "<h1>post 404: not found</h1>")
+	is.NoErr(app.Close())
+}
@@ -1,27 +1,43 @@
 package viewrt
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"net/http"
+	"strings"
+	"sync"
 
 	"github.com/livebud/bud/framework/view/ssr"
 	"github.com/livebud/bud/package/js"
 	"github.com/livebud/bud/package/log"
+	"github.com/livebud/bud/package/middleware"
 )
 
 type FS = fs.FS
 
 func New(fsys FS, log log.Log, vm js.VM) *Handler {
-	return &Handler{http.FS(fsys), fsys, log, vm}
+	return &Handler{hfs: http.FS(fsys), fsys: fsys, log: log, vm: vm}
 }
 
 type Handler struct {
+	// Stacks adds the error's stack to error pages. This is used in development.
+	Stacks bool
+	// ErrorRoutes are the routes of the error pages. Each error page handles
+	// the errors of the routes below it.
+	ErrorRoutes []string
+
 	hfs  http.FileSystem
 	fsys FS
 	log  log.Log
 	vm   js.VM
+
+	mu     sync.Mutex
+	bundle []byte // SSR bundle that's loaded into the VM
 }
 
 func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -43,44 +59,190 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) Renderer(route string, props interface{}) http.Handler {
+	return h.renderer(route, props, map[string]interface{}{})
+}
+
+// ErrorPage renders the closest error page to the route with the status code.
+// ErrorPage returns nil when there's no error page for the route.
+func (h *Handler) ErrorPage(route string) func(status int, err error) http.Handler {
+	if !h.hasErrorPage(route) {
+		return nil
+	}
+	return func(status int, err error) http.Handler {
+		props := map[string]interface{}{
+			"status":  status,
+			"message": err.Error(),
+		}
+		if h.Stacks {
+			props["stack"] = fmt.Sprintf("%+v", err)
+		}
+		return h.renderer(route, map[string]interface{}{}, map[string]interface{}{
+			"error": props,
+		})
+	}
+}
+
+func (h *Handler) renderer(route string, props interface{}, context map[string]interface{}) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		res, err := h.render(route, props)
+		sw := &streamWriter{w: w}
+		err := h.stream(r.Context(), sw, route, props, withRequest(r, context))
+		if err == nil && !sw.wroteHead {
+			err = fmt.Errorf("view: %q didn't write a response", route)
+		}
 		if err != nil {
-			h.log.Field("error", err).Error("view: render error")
+			// Once the head has been written, the status can't change
+			if sw.wroteHead {
+				h.log.Field("error", err).Error("view: render error while streaming")
+				return
+			}
+			// Renders that time out or run out of memory are terminated
+			var terminated *js.TerminatedError
+			if errors.As(err, &terminated) {
+				h.log.Field("error", err).Error("view: render terminated")
+			} else {
+				h.log.Field("error", err).Error("view: render error")
+			}
+			// Render the error page, unless it's the error page that failed
+			if _, ok := context["error"]; !ok {
+				if page := h.ErrorPage(route); page != nil {
+					page(http.StatusInternalServerError, err).ServeHTTP(w, r)
+					return
+				}
+			}
 			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-		headers := w.Header()
-		for key, value := range res.Headers {
-			headers.Set(key, value)
 		}
-		w.WriteHeader(res.Status)
-		w.Write([]byte(res.Body))
 	})
 }
 
-func (h *Handler) render(path string, props interface{}) (*ssr.Response, error) {
+// Check if an error page handles the route. Parameters in the error page's
+// route match any segment. This matches findError in the SSR runtime.
+func (h *Handler) hasErrorPage(route string) bool {
+	segments := splitRoute(route)
+	for _, errorRoute := range h.ErrorRoutes {
+		prefix := splitRoute(errorRoute)
+		if len(prefix) > len(segments) {
+			continue
+		}
+		matches := true
+		for i, segment := range prefix {
+			if segment != segments[i] && !strings.HasPrefix(segment, ":") {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return true
+		}
+	}
+	return false
+}
+
+func splitRoute(route string) (segments []string) {
+	for _, segment := range strings.Split(route, "/") {
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	return segments
+}
+
+// Add the request's values to the render context
+func withRequest(r *http.Request, context map[string]interface{}) map[string]interface{} {
+	token := middleware.CSRFToken(r.Context())
+	if token == "" {
+		return context
+	}
+	merged := make(map[string]interface{}, len(context)+1)
+	for key, value := range context {
+		merged[key] = value
+	}
+	merged["csrf"] = token
+	return merged
+}
+
+// Stream the view to w. The first line is the response head, followed by the
+// body as it's rendered.
+func (h *Handler) stream(ctx context.Context, w io.Writer, path string, props interface{}, context map[string]interface{}) error {
 	propBytes, err := json.Marshal(props)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	script, err := fs.ReadFile(h.fsys, "bud/view/_ssr.js")
+	contextBytes, err := json.Marshal(context)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	// Evaluate the server
-	expr := fmt.Sprintf(`%s; bud.render(%q, %s)`, script, path, propBytes)
-	result, err := h.vm.Eval("_ssr.js", expr)
-	if err != nil {
-		return nil, err
+	if err := h.load(); err != nil {
+		return err
+	}
+	expr := fmt.Sprintf(`bud.stream(%q, %s, %s)`, path, propBytes, contextBytes)
+	return h.vm.Stream(ctx, "_ssr.js", expr, w)
+}
+
+// streamWriter reads the response head from the first line of the stream, then
+// flushes the body to the client as it's written
+type streamWriter struct {
+	w         http.ResponseWriter
+	head      []byte // Buffered until the first newline
+	wroteHead bool
+}
+
+func (s *streamWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	if !s.wroteHead {
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			s.head = append(s.head, p...)
+			return n, nil
+		}
+		s.head = append(s.head, p[:i]...)
+		if err := s.writeHead(); err != nil {
+			return 0, err
+		}
+		p = p[i+1:]
+	}
+	if len(p) > 0 {
+		if _, err := s.w.Write(p); err != nil {
+			return 0, err
+		}
+	}
+	if flusher, ok := s.w.(http.Flusher); ok {
+		flusher.Flush()
 	}
-	// Unmarshal the response
+	return n, nil
+}
+
+func (s *streamWriter) writeHead() error {
 	res := new(ssr.Response)
-	if err := json.Unmarshal([]byte(result), res); err != nil {
-		return nil, err
+	if err := json.Unmarshal(s.head, res); err != nil {
+		return fmt.Errorf("view: unable to read the response head. %w", err)
 	}
 	if res.Status < 100 || res.Status > 999 {
-		return nil, fmt.Errorf("view: invalid status code %d", res.Status)
+		return fmt.Errorf("view: invalid status code %d", res.Status)
+	}
+	headers := s.w.Header()
+	for key, value := range res.Headers {
+		headers.Set(key, value)
+	}
+	s.w.WriteHeader(res.Status)
+	s.wroteHead = true
+	return nil
+}
+
+// Load the SSR bundle into the VM when it changes, so renders only need to
+// evaluate the render call. Pooled VMs load the bundle once per VM.
+func (h *Handler) load() error {
+	script, err := fs.ReadFile(h.fsys, "bud/view/_ssr.js")
+	if err != nil {
+		return err
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if bytes.Equal(script, h.bundle) {
+		return nil
+	}
+	if err := h.vm.Script("_ssr.js", string(script)); err != nil {
+		return err
 	}
-	return res, nil
+	h.bundle = script
+	return nil
 }
@@ -1,6 +1,8 @@
 package web
 
 import (
+	"errors"
+	"fmt"
 	"io/fs"
 	"path"
 	"path/filepath"
@@ -48,7 +50,7 @@ func (l *loader) Load() (state *State, err error) {
 		return nil, err
 	}
 	// Add initial imports
-	l.imports.AddStd("net/http", "context")
+	l.imports.AddStd("net/http", "context", "io")
 	l.imports.AddNamed("middleware", "github.com/livebud/bud/package/middleware")
 	l.imports.AddNamed("webrt", "github.com/livebud/bud/framework/web/webrt")
 	l.imports.AddNamed("router", "github.com/livebud/bud/package/router")
@@ -69,8 +71,14 @@ func (l *loader) Load() (state *State, err error) {
 	}
 	// Load the resources
 	for _, webDir := range webDirs {
-		state.Resources = append(state.Resources, l.loadResource(webDir))
+		resource := l.loadResource(webDir)
+		if webDir == "bud/internal/web/view" {
+			state.View = resource
+		}
+		state.Resources = append(state.Resources, resource)
 	}
+	// Load the app's middleware
+	state.Middleware = l.loadMiddleware()
 	// Load the imports
 	state.Imports = l.imports.List()
 	return state, nil
@@ -88,6 +96,89 @@ func (l *loader) loadResource(webDir string) (resource *Resource) {
 	return resource
 }
 
+// Load the middleware from the app's middleware/ directory. The package either
+// exports a Middleware or Stack type that implements middleware.Middleware, or
+// a Stack type that's a list of middleware.
+func (l *loader) loadMiddleware() *Middleware {
+	des, err := fs.ReadDir(l.fsys, "middleware")
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		l.Bail(err)
+	}
+	shouldParse := false
+	for _, de := range des {
+		if !de.IsDir() && valid.GoFile(de.Name()) {
+			shouldParse = true
+			break
+		}
+	}
+	if !shouldParse {
+		return nil
+	}
+	pkg, err := l.parser.Parse("middleware")
+	if err != nil {
+		l.Bail(err)
+	}
+	name := l.imports.Add(l.module.Import("middleware"))
+	for _, typeName := range []string{"Middleware", "Stack"} {
+		spec := findTypeSpec(pkg, typeName)
+		if spec == nil {
+			continue
+		}
+		_, isStruct := spec.Type().(*parser.StructType)
+		if hasMiddlewareMethod(pkg, typeName) {
+			if isStruct {
+				return &Middleware{Type: "*" + name + "." + typeName, Expr: "appMiddleware"}
+			}
+			return &Middleware{Type: name + "." + typeName, Expr: "appMiddleware"}
+		}
+		// Convert a list of middleware into a stack
+		if typeName == "Stack" && isMiddlewareList(spec.Type()) {
+			return &Middleware{Type: name + "." + typeName, Expr: "middleware.Stack(appMiddleware)"}
+		}
+	}
+	l.Bail(fmt.Errorf("web: middleware/ must export a Middleware or Stack type that implements middleware.Middleware"))
+	return nil
+}
+
+func findTypeSpec(pkg *parser.Package, name string) *parser.TypeSpec {
+	for _, spec := range pkg.TypeSpecs() {
+		if spec.Name() == name {
+			return spec
+		}
+	}
+	return nil
+}
+
+func hasMiddlewareMethod(pkg *parser.Package, typeName string) bool {
+	for _, fn := range pkg.Functions() {
+		recv := fn.Receiver()
+		if recv != nil && fn.Name() == "Middleware" && parser.TypeName(recv.Type()) == typeName {
+			return true
+		}
+	}
+	return false
+}
+
+// isMiddlewareList checks if the type is a []middleware.Middleware
+func isMiddlewareList(t parser.Type) bool {
+	list, ok := t.(*parser.ArrayType)
+	if !ok {
+		return false
+	}
+	inner, ok := list.Inner().(*parser.SelectorType)
+	if !ok || inner.Name() != "Middleware" {
+		return false
+	}
+	importPath, err := inner.ImportPath()
+	if err != nil {
+		return false
+	}
+	return importPath == "github.com/livebud/bud/package/middleware"
+}
+
 func shouldShowWelcome(fsys fs.FS, webDirs []string) (bool, error) {
 	if len(webDirs) == 0 {
 		return true, nil
@@ -3,8 +3,16 @@ package web
 import "github.com/livebud/bud/internal/imports"
 
 type State struct {
-	Imports   []*imports.Import
-	Resources []*Resource
+	Imports    []*imports.Import
+	Resources  []*Resource
+	Middleware *Middleware
+	View       *Resource // Renders the error page for 404s
+}
+
+// Middleware from the app's middleware/ directory
+type Middleware struct {
+	Type string // Type that's injected into web.New
+	Expr string // Expression that implements middleware.Middleware
 }
 
 // Resource is a web package that will register its routes
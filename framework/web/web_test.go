@@ -24,3 +24,158 @@ func TestEmptyBuild(t *testing.T) {
 	// Empty builds generate the web directory
 	is.NoErr(td.Exists("bud/internal/web"))
 }
+
+func TestMiddlewareStruct(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	td := testdir.New(dir)
+	td.Files["middleware/middleware.go"] = `
+		package middleware
+		import (
+			"net/http"
+			"github.com/livebud/bud/package/log"
+		)
+		type Middleware struct {
+			Log log.Log
+		}
+		func (m *Middleware) Middleware(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				m.Log.Info("request " + r.Method + " " + r.URL.Path)
+				w.Header().Set("X-Powered-By", "bud")
+				next.ServeHTTP(w, r)
+			})
+		}
+	`
+	td.Files["controller/controller.go"] = `
+		package controller
+		type Controller struct {}
+		func (c *Controller) Index() string { return "hello" }
+	`
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(dir)
+	app, err := cli.Start(ctx, "run")
+	is.NoErr(err)
+	defer app.Close()
+	res, err := app.Get("/")
+	is.NoErr(err)
+	is.Equal(res.Status(), 200)
+	is.Equal(res.Header("X-Powered-By"), "bud")
+	// Middleware also runs before 404s
+	res, err = app.Get("/missing")
+	is.NoErr(err)
+	is.Equal(res.Status(), 404)
+	is.Equal(res.Header("X-Powered-By"), "bud")
+	is.NoErr(app.Close())
+}
+
+func TestMiddlewareStack(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	td := testdir.New(dir)
+	td.Files["middleware/middleware.go"] = `
+		package middleware
+		import (
+			"net/http"
+			"github.com/livebud/bud/package/middleware"
+		)
+		type Stack []middleware.Middleware
+		func New() Stack {
+			return Stack{header("X-First", "1"), header("X-Second", "2")}
+		}
+		func header(key, value string) middleware.Middleware {
+			return middleware.Function(func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set(key, value)
+					next.ServeHTTP(w, r)
+				})
+			})
+		}
+	`
+	td.Files["controller/controller.go"] = `
+		package controller
+		type Controller struct {}
+		func (c *Controller) Index() string { return "hello" }
+	`
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(dir)
+	app, err := cli.Start(ctx, "run")
+	is.NoErr(err)
+	defer app.Close()
+	res, err := app.Get("/")
+	is.NoErr(err)
+	is.Equal(res.Status(), 200)
+	is.Equal(res.Header("X-First"), "1")
+	is.Equal(res.Header("X-Second"), "2")
+	is.NoErr(app.Close())
+}
+
+func TestMiddlewareInvalid(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	td := testdir.New(dir)
+	td.Files["middleware/middleware.go"] = `
+		package middleware
+		type Logger struct {}
+	`
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(dir)
+	_, err := cli.Run(ctx, "build")
+	is.True(err != nil)
+	is.In(err.Error(), "middleware/ must export a Middleware or Stack type")
+}
+
+func TestMiddlewareStackInvalid(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	td := testdir.New(dir)
+	td.Files["middleware/middleware.go"] = `
+		package middleware
+		type Stack []string
+	`
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(dir)
+	_, err := cli.Run(ctx, "build")
+	is.True(err != nil)
+	is.In(err.Error(), "middleware/ must export a Middleware or Stack type")
+}
+
+func TestRouteConflict(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	td := testdir.New(dir)
+	td.Files["controller/controller.go"] = `
+		package controller
+		type Controller struct {}
+		func (c *Controller) About() string { return "about" }
+	`
+	td.BFiles["public/about"] = []byte("about")
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(dir)
+	_, err := cli.Run(ctx, "build")
+	is.True(err != nil)
+	is.In(err.Error(), "routes: GET /about is defined in both")
+	is.In(err.Error(), "public/about")
+}
+
+func TestRouteShadow(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	td := testdir.New(dir)
+	td.Files["controller/users/controller.go"] = `
+		package users
+		type Controller struct {}
+		func (c *Controller) Show(id string) string { return id }
+	`
+	td.BFiles["public/users/settings"] = []byte("settings")
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(dir)
+	result, err := cli.Run(ctx, "build")
+	is.NoErr(err)
+	is.In(result.Stderr(), "routes: GET /users/settings in public/users/settings shadows GET /users/:id in users/show")
+}
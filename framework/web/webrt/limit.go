@@ -0,0 +1,23 @@
+package webrt
+
+import (
+	"net/http"
+
+	"github.com/livebud/bud/framework/controller/controllerrt/request"
+)
+
+// LimitBody caps request bodies at request.MaxBytes before the middleware
+// runs, so middleware that parses the body, like the CSRF check, is held to the
+// same limit as the actions
+func LimitBody(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength > request.MaxBytes {
+			http.Error(w, request.ErrRequestTooLarge.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		if r.Body != nil && r.Body != http.NoBody {
+			r.Body = http.MaxBytesReader(w, r.Body, request.MaxBytes)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
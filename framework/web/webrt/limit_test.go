@@ -0,0 +1,75 @@
+package webrt_test
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/livebud/bud/framework/controller/controllerrt/request"
+	"github.com/livebud/bud/framework/web/webrt"
+	"github.com/livebud/bud/internal/is"
+	"github.com/livebud/bud/package/middleware"
+)
+
+func TestLimitBody(t *testing.T) {
+	is := is.New(t)
+	maxBytes := request.MaxBytes
+	request.MaxBytes = 10
+	defer func() { request.MaxBytes = maxBytes }()
+	handler := webrt.LimitBody(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		w.Write(body)
+	}))
+	// Small bodies pass through
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("hello"))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	is.Equal(rec.Code, 200)
+	is.Equal(rec.Body.String(), "hello")
+	// Large bodies are rejected upfront
+	req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader("hello world"))
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	is.Equal(rec.Code, 413)
+	// Large bodies of unknown length fail while reading
+	req = httptest.NewRequest(http.MethodPost, "/", io.NopCloser(strings.NewReader("hello world")))
+	req.ContentLength = -1
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	is.Equal(rec.Code, 413)
+}
+
+func TestLimitBodyCSRF(t *testing.T) {
+	is := is.New(t)
+	maxBytes := request.MaxBytes
+	request.MaxBytes = 1 << 10
+	defer func() { request.MaxBytes = maxBytes }()
+	called := false
+	handler := webrt.LimitBody(middleware.CSRF().Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	})))
+	// Multipart upload that's larger than the limit
+	body := new(bytes.Buffer)
+	form := multipart.NewWriter(body)
+	form.WriteField("_csrf", "token")
+	file, err := form.CreateFormFile("file", "upload.txt")
+	is.NoErr(err)
+	file.Write(bytes.Repeat([]byte("a"), 4<<10))
+	is.NoErr(form.Close())
+	req := httptest.NewRequest(http.MethodPost, "/", io.NopCloser(body))
+	req.ContentLength = -1
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.AddCookie(&http.Cookie{Name: middleware.CSRFCookie, Value: "token"})
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	is.Equal(rec.Code, 403)
+	is.True(!called)
+}
@@ -0,0 +1,27 @@
+package webrt
+
+import (
+	"net/http"
+
+	"github.com/livebud/bud/framework/controller/controllerrt/request"
+	"github.com/livebud/bud/framework/controller/controllerrt/response"
+)
+
+// NotFound responds to requests that didn't match a route with the closest
+// error page. JSON requests receive the JSON error. Routes without an error
+// page and requests that accept neither, like images, receive a plain 404.
+func NotFound(errorPage func(route string) func(status int, err error) http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := errorPage(r.URL.Path)
+		if page == nil {
+			http.NotFound(w, r)
+			return
+		}
+		acceptable := request.Accepts(r)
+		if !acceptable.Accepts("text/html") && !acceptable.Accepts("application/json") {
+			http.NotFound(w, r)
+			return
+		}
+		response.Error(r, response.ErrNotFound, page).ServeHTTP(w, r)
+	})
+}
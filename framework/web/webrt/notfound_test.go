@@ -0,0 +1,58 @@
+package webrt_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/livebud/bud/framework/web/webrt"
+	"github.com/livebud/bud/internal/is"
+)
+
+// Error page for every route except /api
+func errorPage(route string) func(status int, err error) http.Handler {
+	if strings.HasPrefix(route, "/api") {
+		return nil
+	}
+	return func(status int, err error) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+			fmt.Fprintf(w, "<h1>%d %s at %s</h1>", status, err, route)
+		})
+	}
+}
+
+func TestNotFound(t *testing.T) {
+	is := is.New(t)
+	handler := webrt.NotFound(errorPage)
+	// HTML requests render the error page
+	req := httptest.NewRequest(http.MethodGet, "/posts/10", nil)
+	req.Header.Set("Accept", "text/html")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	is.Equal(rec.Code, 404)
+	is.Equal(rec.Body.String(), "<h1>404 not found at /posts/10</h1>")
+	// JSON requests receive the JSON error
+	req = httptest.NewRequest(http.MethodGet, "/posts/10", nil)
+	req.Header.Set("Accept", "application/json")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	is.Equal(rec.Code, 404)
+	is.Equal(rec.Body.String(), `{"error":"not found"}`)
+	// Other requests receive a plain 404
+	req = httptest.NewRequest(http.MethodGet, "/logo.png", nil)
+	req.Header.Set("Accept", "image/png")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	is.Equal(rec.Code, 404)
+	is.Equal(rec.Body.String(), "404 page not found\n")
+	// Routes without an error page receive a plain 404
+	req = httptest.NewRequest(http.MethodGet, "/api/posts", nil)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	is.Equal(rec.Code, 404)
+	is.Equal(rec.Header().Get("X-Content-Type-Options"), "nosniff")
+	is.Equal(rec.Body.String(), "404 page not found\n")
+}
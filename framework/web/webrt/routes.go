@@ -0,0 +1,40 @@
+package webrt
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/livebud/bud/package/router"
+)
+
+// PrintRoutes writes the routes registered on the router as a table, or as
+// JSON when asJSON is true
+func PrintRoutes(w io.Writer, router *router.Router, asJSON bool) error {
+	routes := router.Routes()
+	if asJSON {
+		list := make([]*jsonRoute, len(routes))
+		for i, route := range routes {
+			list[i] = &jsonRoute{route.Method, route.Route, route.Host}
+		}
+		out, err := json.MarshalIndent(list, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, string(out))
+		return nil
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "METHOD\tROUTE\tHOST")
+	for _, route := range routes {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", route.Method, route.Route, route.Host)
+	}
+	return tw.Flush()
+}
+
+type jsonRoute struct {
+	Method string `json:"method"`
+	Route  string `json:"route"`
+	Host   string `json:"host,omitempty"`
+}
@@ -0,0 +1,36 @@
+package webrt_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/livebud/bud/framework/web/webrt"
+	"github.com/livebud/bud/internal/is"
+	"github.com/livebud/bud/package/router"
+)
+
+func TestPrintRoutes(t *testing.T) {
+	is := is.New(t)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	rt := router.New()
+	is.NoErr(rt.Get("/posts", handler))
+	is.NoErr(rt.Post("/posts/:id/publish", handler))
+	out := new(bytes.Buffer)
+	is.NoErr(webrt.PrintRoutes(out, rt, false))
+	is.Equal(out.String(), ""+
+		"METHOD  ROUTE               HOST\n"+
+		"GET     /posts              \n"+
+		"POST    /posts/:id/publish  \n")
+	out.Reset()
+	is.NoErr(webrt.PrintRoutes(out, rt, true))
+	var routes []struct {
+		Method string `json:"method"`
+		Route  string `json:"route"`
+	}
+	is.NoErr(json.Unmarshal(out.Bytes(), &routes))
+	is.Equal(len(routes), 2)
+	is.Equal(routes[1].Method, "POST")
+	is.Equal(routes[1].Route, "/posts/:id/publish")
+}
@@ -170,6 +170,13 @@ func (c *CLI) Parse(ctx context.Context, args ...string) error {
 		cli.Run(func(ctx context.Context) error { return c.Build(ctx, in) })
 	}
 
+	{ // $ bud routes
+		in := &Routes{Flag: &framework.Flag{}}
+		cli := cli.Command("routes", "list the routes of your app")
+		cli.Flag("json", "print the routes as JSON").Bool(&in.JSON).Default(false)
+		cli.Run(func(ctx context.Context) error { return c.Routes(ctx, in) })
+	}
+
 	{ // $ bud new
 		cli := cli.Command("new", "scaffold code for your app")
 
@@ -254,6 +261,12 @@ func (c *CLI) Parse(ctx context.Context, args ...string) error {
 			}
 		}
 
+		{ // $ bud tool openapi
+			in := &ToolOpenAPI{Flag: &framework.Flag{}}
+			cli := cli.Command("openapi", "print the OpenAPI document for the controllers")
+			cli.Run(func(ctx context.Context) error { return c.ToolOpenAPI(ctx, in) })
+		}
+
 		{ // $ bud tool v8
 			in := &ToolV8{}
 			cli := cli.Command("v8", "execute Javascript with V8 from stdin")
@@ -3,6 +3,7 @@ package cli
 import (
 	"context"
 
+	"github.com/livebud/bud/framework"
 	"github.com/livebud/bud/internal/once"
 	"github.com/livebud/bud/package/commander"
 )
@@ -13,9 +14,26 @@ type Custom struct {
 	Args   []string
 }
 
+// Custom runs the app's custom commands (e.g. `bud db migrate` runs
+// `bud/app db migrate`)
 func (c *CLI) Custom(ctx context.Context, in *Custom) error {
-	if in.Help {
+	if in.Help || len(in.Args) == 0 {
 		return commander.Usage()
 	}
-	return commander.Usage()
+
+	// Find the module if not already provided
+	module, err := c.findModule()
+	if err != nil {
+		return err
+	}
+
+	// Generate bud files and build the app binary
+	generate := &Generate{Flag: &framework.Flag{}}
+	if err := c.Generate(ctx, generate); err != nil {
+		return err
+	}
+
+	// Forward the arguments to the app binary
+	cmd := c.command(module.Directory(), module.Directory(appBinPath), in.Args...)
+	return cmd.Run()
 }
@@ -112,6 +112,7 @@ func TestNewControllerAll(t *testing.T) {
 	is.NoErr(err)
 	is.In(html, `<h1>New Post</h1>`)
 	is.In(html, `<form method="post" action="/posts">`)
+	is.In(html, `<input type="hidden" name="_csrf" value=""/>`)
 	is.In(html, `<input type="submit" value="Create Post"/>`)
 	is.In(html, `</form>`)
 	is.In(html, `<a href="/posts">Back</a>`)
@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/livebud/bud/framework"
+)
+
+type Routes struct {
+	Flag      *framework.Flag
+	ListenAFS string
+	ListenDev string
+	JSON      bool
+}
+
+func (c *CLI) Routes(ctx context.Context, in *Routes) error {
+	module, err := c.findModule()
+	if err != nil {
+		return err
+	}
+	afsLn, err := c.listenAFS(in.ListenAFS)
+	if err != nil {
+		return err
+	}
+	devLn, err := c.listenDev(in.ListenDev)
+	if err != nil {
+		return err
+	}
+
+	// Build the app
+	generate := &Generate{
+		Flag:      in.Flag,
+		ListenAFS: in.ListenAFS,
+		ListenDev: in.ListenDev,
+	}
+	if err := c.Generate(ctx, generate); err != nil {
+		return err
+	}
+
+	// Print the routes that the app's router registers
+	args := []string{"routes"}
+	if in.JSON {
+		args = append(args, "--json")
+	}
+	cmd := c.command(module.Directory(), filepath.Join("bud", "app"), args...)
+	cmd.Env = append(cmd.Env,
+		"BUD_AFS_URL="+afsLn.Addr().String(),
+		"BUD_DEV_URL="+devLn.Addr().String(),
+	)
+	return cmd.Run()
+}
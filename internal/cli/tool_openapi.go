@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/livebud/bud/framework"
+)
+
+type ToolOpenAPI struct {
+	Flag *framework.Flag
+}
+
+func (c *CLI) ToolOpenAPI(ctx context.Context, in *ToolOpenAPI) error {
+	module, err := c.findModule()
+	if err != nil {
+		return err
+	}
+
+	// Generate the OpenAPI document
+	generate := &Generate{
+		Flag:     in.Flag,
+		Packages: []string{"bud/openapi.json"},
+	}
+	if err := c.Generate(ctx, generate); err != nil {
+		return err
+	}
+
+	// Read the document out
+	document, err := os.ReadFile(module.Directory("bud/openapi.json"))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("cli: there are no controller actions to describe")
+		}
+		return err
+	}
+
+	// Print it out
+	fmt.Fprintln(c.Stdout, string(document))
+	return nil
+}
@@ -17,6 +17,16 @@ func FindByPage(fsys fs.FS, page string) (view *View, err error) {
 			return view, nil
 		}
 	}
+	// Error pages are also compiled as views
+	errors, err := ListErrors(fsys, "view")
+	if err != nil {
+		return nil, err
+	}
+	for _, view := range errors {
+		if string(view.Page) == page {
+			return view, nil
+		}
+	}
 	return nil, fmt.Errorf("unable to find view by page %q", page)
 }
 
@@ -15,13 +15,14 @@ import (
 
 // List the views
 func List(fsys fs.FS, paths ...string) ([]*View, error) {
+	root := path.Clean(path.Join(paths...))
 	// Build a tree of reserved views (layout, frames, error)
-	tree, err := buildTree(fsys, path.Clean(path.Join(paths...)))
+	tree, err := buildTree(fsys, root)
 	if err != nil {
 		return nil, err
 	}
 	// Turn the tree of views into a list of views
-	views, err := listViews(fsys, tree, path.Clean(path.Join(paths...)))
+	views, err := listViews(fsys, tree, root, root)
 	if err != nil {
 		return nil, err
 	}
@@ -32,6 +33,22 @@ func List(fsys fs.FS, paths ...string) ([]*View, error) {
 	return views, nil
 }
 
+// ListErrors lists the error pages as views. The route of an error page is the
+// directory it's in, so it handles the errors of every route below it that
+// doesn't have a closer error page.
+func ListErrors(fsys fs.FS, paths ...string) ([]*View, error) {
+	root := path.Clean(path.Join(paths...))
+	tree, err := buildTree(fsys, root)
+	if err != nil {
+		return nil, err
+	}
+	views := listErrors(tree, tree, root, root)
+	sort.Slice(views, func(i, j int) bool {
+		return views[i].Page < views[j].Page
+	})
+	return views, nil
+}
+
 func buildTree(fsys fs.FS, dir string) (*tree, error) {
 	fis, err := fs.ReadDir(fsys, dir)
 	if err != nil {
@@ -123,7 +140,17 @@ func splitRoot(dir string) (root, rest string) {
 	return parts[0], parts[1]
 }
 
-func listViews(fsys fs.FS, tree *tree, dir string) (views []*View, err error) {
+// Path of the directory within the tree
+func relative(root, dir string) string {
+	if root == "." {
+		return dir
+	} else if dir == root {
+		return ""
+	}
+	return strings.TrimPrefix(dir, root+"/")
+}
+
+func listViews(fsys fs.FS, tree *tree, root, dir string) (views []*View, err error) {
 	fis, err := fs.ReadDir(fsys, dir)
 	if err != nil {
 		return nil, err
@@ -135,7 +162,7 @@ func listViews(fsys fs.FS, tree *tree, dir string) (views []*View, err error) {
 			if !valid.Dir(name) {
 				continue
 			}
-			subviews, err := listViews(fsys, tree, fullpath)
+			subviews, err := listViews(fsys, tree, root, fullpath)
 			if err != nil {
 				return nil, err
 			}
@@ -150,13 +177,14 @@ func listViews(fsys fs.FS, tree *tree, dir string) (views []*View, err error) {
 		if ext != ".svelte" {
 			continue
 		}
+		rel := relative(root, dir)
 		views = append(views, &View{
 			Page:   Path(fullpath),
 			Client: client(fullpath),
 			Route:  route(dir, name),
-			Frames: tree.Frames(dir, ext),
-			Layout: tree.Layout(dir, ext),
-			Error:  tree.Error(dir, ext),
+			Frames: tree.Frames(rel, ext),
+			Layout: tree.Layout(rel, ext),
+			Error:  tree.Error(rel, ext),
 			Type:   strings.TrimPrefix(ext, "."),
 			Hot:    ":35729", // TODO: configurable
 		})
@@ -164,6 +192,29 @@ func listViews(fsys fs.FS, tree *tree, dir string) (views []*View, err error) {
 	return views, nil
 }
 
+// Error pages are rendered within the layout, but they aren't framed or
+// hydrated
+func listErrors(tree, subtree *tree, root, dir string) (views []*View) {
+	for ext, error := range subtree.error {
+		if ext != ".svelte" && ext != ".jsx" {
+			continue
+		}
+		views = append(views, &View{
+			Page:   error,
+			Client: client(string(error)),
+			Route:  route(dir, "index"+ext),
+			Layout: tree.Layout(relative(root, dir), ext),
+			Error:  error,
+			Type:   strings.TrimPrefix(ext, "."),
+			Hot:    ":35729", // TODO: configurable
+		})
+	}
+	for name, subtree := range subtree.subtree {
+		views = append(views, listErrors(tree, subtree, root, path.Join(dir, name))...)
+	}
+	return views
+}
+
 // Generate the IDs for a nested route
 // TODO: consolidate with the function in internal/generator/action/loader.go.
 func routeDir(dir string) string {
@@ -112,3 +112,64 @@ func TestListUnderscore(t *testing.T) {
 	is.Equal(views[1].Client, "bud/_vip_users.svelte.js")
 	is.Equal(views[1].Hot, ":35729")
 }
+
+func TestListNestedFromView(t *testing.T) {
+	is := is.New(t)
+	fsys := vfs.Map{
+		"view/Frame.svelte":                  []byte(""),
+		"view/Layout.svelte":                 []byte(""),
+		"view/Error.svelte":                  []byte(""),
+		"view/user/Frame.svelte":             []byte(""),
+		"view/user/Error.svelte":             []byte(""),
+		"view/user/index.svelte":             []byte(""),
+		"view/visitor/comments/show.svelte":  []byte(""),
+		"view/visitor/comments/Frame.svelte": []byte(""),
+	}
+	views, err := entrypoint.List(fsys, "view")
+	is.NoErr(err)
+	is.Equal(len(views), 2)
+	is.Equal(views[0].Page, entrypoint.Path("view/user/index.svelte"))
+	is.Equal(len(views[0].Frames), 2)
+	is.Equal(views[0].Frames[0], entrypoint.Path("view/Frame.svelte"))
+	is.Equal(views[0].Frames[1], entrypoint.Path("view/user/Frame.svelte"))
+	is.Equal(views[0].Layout, entrypoint.Path("view/Layout.svelte"))
+	is.Equal(views[0].Error, entrypoint.Path("view/user/Error.svelte"))
+	is.Equal(views[1].Page, entrypoint.Path("view/visitor/comments/show.svelte"))
+	is.Equal(len(views[1].Frames), 2)
+	is.Equal(views[1].Frames[0], entrypoint.Path("view/Frame.svelte"))
+	is.Equal(views[1].Frames[1], entrypoint.Path("view/visitor/comments/Frame.svelte"))
+	is.Equal(views[1].Error, entrypoint.Path("view/Error.svelte"))
+}
+
+func TestListErrors(t *testing.T) {
+	is := is.New(t)
+	fsys := vfs.Map{
+		"view/Error.svelte":                  []byte(""),
+		"view/Layout.svelte":                 []byte(""),
+		"view/index.svelte":                  []byte(""),
+		"view/admin/Error.jsx":               []byte(""),
+		"view/admin/Layout.jsx":              []byte(""),
+		"view/visitor/comments/Error.svelte": []byte(""),
+		"view/visitor/comments/Error.md":     []byte(""),
+	}
+	views, err := entrypoint.ListErrors(fsys, "view")
+	is.NoErr(err)
+	is.Equal(len(views), 3)
+	is.Equal(views[0].Page, entrypoint.Path("view/Error.svelte"))
+	is.Equal(views[0].Error, entrypoint.Path("view/Error.svelte"))
+	is.Equal(views[0].Layout, entrypoint.Path("view/Layout.svelte"))
+	is.Equal(views[0].Type, "svelte")
+	is.Equal(views[0].Route, "/")
+	is.Equal(len(views[0].ServerImports()), 2)
+	is.Equal(views[1].Page, entrypoint.Path("view/admin/Error.jsx"))
+	is.Equal(views[1].Layout, entrypoint.Path("view/admin/Layout.jsx"))
+	is.Equal(views[1].Type, "jsx")
+	is.Equal(views[1].Route, "/admin")
+	is.Equal(views[2].Page, entrypoint.Path("view/visitor/comments/Error.svelte"))
+	is.Equal(views[2].Layout, entrypoint.Path("view/Layout.svelte"))
+	is.Equal(views[2].Route, "/visitor/:visitor_id/comments")
+	// Error pages can be found by their page
+	view, err := entrypoint.FindByPage(fsys, "view/admin/Error.jsx")
+	is.NoErr(err)
+	is.Equal(view.Route, "/admin")
+}
@@ -28,7 +28,8 @@ func (v *View) ServerImports() (imports []Path) {
 	if v.Layout != "" {
 		imports = append(imports, v.Layout)
 	}
-	if v.Error != "" {
+	// Error pages are their own error page
+	if v.Error != "" && v.Error != v.Page {
 		imports = append(imports, v.Error)
 	}
 	return imports
@@ -37,7 +38,7 @@ func (v *View) ServerImports() (imports []Path) {
 func (v *View) BrowserImports() (imports []Path) {
 	imports = append(imports, v.Page)
 	imports = append(imports, v.Frames...)
-	if v.Error != "" {
+	if v.Error != "" && v.Error != v.Page {
 		imports = append(imports, v.Error)
 	}
 	return imports
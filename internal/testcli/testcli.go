@@ -25,6 +25,7 @@ import (
 	"github.com/livebud/bud/package/log"
 	"github.com/livebud/bud/package/log/testlog"
 	"github.com/livebud/bud/package/socket"
+	"github.com/livebud/bud/package/websocket"
 
 	"golang.org/x/sync/errgroup"
 
@@ -179,13 +180,14 @@ func (c *CLI) Start(ctx context.Context, args ...string) (*Client, error) {
 	})
 	// App provides helpers and controls for the running CLI
 	client := &Client{
-		eg:     eg,
-		log:    log,
-		bus:    c.bus,
-		stdout: stdout,
-		stderr: stderr,
-		webc:   webc,
-		hotc:   budc,
+		eg:      eg,
+		log:     log,
+		bus:     c.bus,
+		stdout:  stdout,
+		stderr:  stderr,
+		webc:    webc,
+		hotc:    budc,
+		webAddr: webLn.Addr().String(),
 		// Close function
 		close: func() error {
 			// Cancel the CLI
@@ -204,15 +206,16 @@ func (c *CLI) Start(ctx context.Context, args ...string) (*Client, error) {
 
 // Client for interacting with the running app
 type Client struct {
-	eg     *errgroup.Group
-	log    log.Log
-	bus    pubsub.Client
-	stdout *bytes.Buffer
-	stderr *bytes.Buffer
-	webc   *http.Client
-	hotc   *http.Client
-	once   once.Error
-	close  func() error
+	eg      *errgroup.Group
+	log     log.Log
+	bus     pubsub.Client
+	stdout  *bytes.Buffer
+	stderr  *bytes.Buffer
+	webc    *http.Client
+	hotc    *http.Client
+	webAddr string
+	once    once.Error
+	close   func() error
 }
 
 // Stdout at a point in time
@@ -239,6 +242,15 @@ func (c *Client) Hot(path string) (*hot.Stream, error) {
 	return hot.DialWith(c.hotc, c.log, getURL(path))
 }
 
+// Dial a WebSocket on the app
+func (c *Client) Dial(ctx context.Context, path string) (*websocket.Conn, error) {
+	conn, err := socket.Dial(ctx, c.webAddr)
+	if err != nil {
+		return nil, err
+	}
+	return websocket.NewClient(ctx, conn, getURL(path))
+}
+
 func bufferHeaders(res *http.Response, body []byte) ([]byte, error) {
 	// Coerce mime types before buffering the header
 	if err := coerceMimes(res); err != nil {
@@ -388,6 +400,12 @@ func (c *Client) GetJSON(path string) (*Response, error) {
 	return do(c.webc, req)
 }
 
+// GetRequest creates a GET request to the app without sending it. Set headers
+// or cookies on the request, then send it with Do.
+func (c *Client) GetRequest(path string) (*http.Request, error) {
+	return getRequest(path)
+}
+
 func getRequest(path string) (*http.Request, error) {
 	return http.NewRequest(http.MethodGet, getURL(path), nil)
 }
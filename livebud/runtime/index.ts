@@ -46,6 +46,16 @@ export function mount(input: MountInput): void {
   }
 }
 
+/**
+ * Read the CSRF token that the server rendered into the page's
+ * <meta name="csrf-token"> tag
+ */
+
+export function csrfToken(): string {
+  const meta = document.querySelector('meta[name="csrf-token"]')
+  return (meta && meta.getAttribute("content")) || ""
+}
+
 function getProps(node: HTMLElement | null) {
   if (!node || !node.textContent) {
     return {}
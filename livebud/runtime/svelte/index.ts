@@ -1,7 +1,14 @@
-import { HydrateInput } from ".."
+import { HydrateInput, csrfToken } from ".."
+import {
+  claim_component,
+  create_component,
+  destroy_component,
+  mount_component,
+  transition_in,
+  transition_out,
+} from "svelte/internal"
 
 // TODO:
-// - Support frames
 // - Handle errors
 export default function createView(input: HydrateInput) {
   if (input.target != null) {
@@ -12,9 +19,61 @@ export default function createView(input: HydrateInput) {
     // For now, we'll clear the DOM in our target before hydrating.
     input.target.innerHTML = ""
   }
-  new input.page({
+  const context = new Map([["csrf", csrfToken()]])
+  // The first frame is on the outside, just like the server-rendered page
+  const [outer, ...inner] = [...input.frames, input.page]
+  new outer({
     target: input.target,
-    props: input.props,
+    props: withSlot(inner, input.props, context),
     hydrate: true,
+    context: context,
   })
 }
+
+// Props for a frame that renders the rest of the components in its default
+// slot. Frames receive the page's props.
+function withSlot(components: any[], props: Record<string, any>, context: Map<string, any>) {
+  if (components.length === 0) {
+    return props
+  }
+  const [outer, ...inner] = components
+  return {
+    ...props,
+    $$slots: {
+      default: [createSlot(outer, withSlot(inner, props, context), context)],
+    },
+    $$scope: {
+      ctx: [],
+    },
+  }
+}
+
+// Create a slot that mounts the component. This is the block that Svelte's
+// compiler would generate for <Component {...props} /> within a slot.
+function createSlot(Component: any, props: Record<string, any>, context: Map<string, any>) {
+  return function () {
+    // Dev builds require $$inline for components without a target
+    const component = new Component({ props, context, $$inline: true })
+    return {
+      c() {
+        create_component(component.$$.fragment)
+      },
+      l(nodes: ChildNode[]) {
+        claim_component(component.$$.fragment, nodes)
+      },
+      m(target: Node, anchor: Node) {
+        mount_component(component, target, anchor, null)
+      },
+      p() {},
+      i(local: boolean) {
+        transition_in(component.$$.fragment, local)
+      },
+      o(local: boolean) {
+        transition_out(component.$$.fragment, local, null, null)
+      },
+      d(detaching: boolean) {
+        destroy_component(component, detaching)
+      },
+    }
+  }
+}
@@ -1,6 +1,7 @@
 package budsvr
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -43,6 +44,7 @@ func newHandler(flag *framework.Flag, fsys fs.FS, bus pubsub.Client, log log.Log
 	// Support eval
 	router.Post("/js/script", http.HandlerFunc(server.script))
 	router.Post("/js/eval", http.HandlerFunc(server.eval))
+	router.Post("/js/stream", http.HandlerFunc(server.stream))
 	return server
 }
 
@@ -79,9 +81,11 @@ func (h *Handler) render(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	route := "/" + r.URL.Query().Get("route")
+	route := "/" + router.Params(r).Get("route")
 	expr := fmt.Sprintf(`%s; bud.render(%q, %s)`, script, route, body)
-	result, err := h.vm.Eval("_ssr.js", expr)
+	ctx, cancel := context.WithTimeout(r.Context(), js.DefaultTimeout)
+	defer cancel()
+	result, err := h.vm.EvalContext(ctx, "_ssr.js", expr)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -90,7 +94,7 @@ func (h *Handler) render(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) open(w http.ResponseWriter, r *http.Request) {
-	path := r.URL.Query().Get("path")
+	path := router.Params(r).Get("path")
 	h.log.Field("file", path).Debug("devserver: opening")
 	file, err := h.fsys.Open(path)
 	if err != nil {
@@ -164,8 +168,16 @@ func (h *Handler) eval(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	result, err := h.vm.Eval(eval.Path, eval.Expr)
+	// Terminate evaluations that take too long or that the client cancelled
+	ctx, cancel := context.WithTimeout(r.Context(), js.DefaultTimeout)
+	defer cancel()
+	result, err := h.vm.EvalContext(ctx, eval.Path, eval.Expr)
 	if err != nil {
+		var terminated *js.TerminatedError
+		if errors.As(err, &terminated) {
+			http.Error(w, terminated.Cause.Error(), budhttp.StatusTerminated)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -173,3 +185,43 @@ func (h *Handler) eval(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte(result))
 }
+
+func (h *Handler) stream(w http.ResponseWriter, r *http.Request) {
+	// Read the body
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var eval budhttp.Eval
+	if err := json.Unmarshal(body, &eval); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), js.DefaultTimeout)
+	defer cancel()
+	// The status is sent with the first chunk, so errors are sent as trailers
+	w.Header().Set("Trailer", budhttp.TrailerError+", "+budhttp.TrailerTerminated)
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if err := h.vm.Stream(ctx, eval.Path, eval.Expr, &flushWriter{w}); err != nil {
+		var terminated *js.TerminatedError
+		if errors.As(err, &terminated) {
+			w.Header().Set(budhttp.TrailerTerminated, terminated.Cause.Error())
+			return
+		}
+		w.Header().Set(budhttp.TrailerError, err.Error())
+	}
+}
+
+// flushWriter flushes after every write, so chunks are sent as they're produced
+type flushWriter struct {
+	w http.ResponseWriter
+}
+
+func (f *flushWriter) Write(p []byte) (int, error) {
+	n, err := f.w.Write(p)
+	if flusher, ok := f.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return n, err
+}
@@ -3,11 +3,16 @@ package budsvr_test
 import (
 	"context"
 	"errors"
+	"io"
+	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/livebud/bud/framework"
 	"github.com/livebud/bud/internal/is"
 	"github.com/livebud/bud/internal/pubsub"
+	"github.com/livebud/bud/internal/urlx"
+	"github.com/livebud/bud/package/budhttp"
 	"github.com/livebud/bud/package/budhttp/budsvr"
 	v8 "github.com/livebud/bud/package/js/v8"
 	"github.com/livebud/bud/package/log/testlog"
@@ -144,3 +149,48 @@ func TestServerWaitNoServe(t *testing.T) {
 	is.NoErr(server.Wait())
 	is.NoErr(server.Wait())
 }
+
+func TestSlots(t *testing.T) {
+	is := is.New(t)
+	log := testlog.New()
+	fsys := virtual.Map{
+		"bud/view/_ssr.js": &virtual.File{
+			Data: []byte(`var bud = { render: (route, props) => JSON.stringify({ route, props }) }`),
+		},
+		"bud/view/_index.svelte.js": &virtual.File{
+			Data: []byte(`export default "index"`),
+		},
+	}
+	vm, err := v8.Load()
+	is.NoErr(err)
+	bus := pubsub.New()
+	budln, err := socket.Listen(":0")
+	is.NoErr(err)
+	defer budln.Close()
+	flag := new(framework.Flag)
+	server := budsvr.New(budln, bus, flag, fsys, log, vm)
+	server.Start(context.Background())
+	defer server.Close()
+	// The route slot is the view to render
+	transport, err := socket.Transport(server.Address())
+	is.NoErr(err)
+	url, err := urlx.Parse(server.Address())
+	is.NoErr(err)
+	httpClient := &http.Client{Transport: transport}
+	res, err := httpClient.Post(url.String()+"/bud/view/posts/show", "application/json", strings.NewReader(`{"id":1}`))
+	is.NoErr(err)
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	is.NoErr(err)
+	is.Equal(res.StatusCode, 200)
+	is.Equal(string(body), `{"route":"/posts/show","props":{"id":1}}`)
+	// The path slot is the file to open
+	client, err := budhttp.Load(log, server.Address())
+	is.NoErr(err)
+	file, err := client.Open("bud/view/_index.svelte.js")
+	is.NoErr(err)
+	defer file.Close()
+	code, err := io.ReadAll(file)
+	is.NoErr(err)
+	is.Equal(string(code), `export default "index"`)
+}
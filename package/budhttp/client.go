@@ -2,11 +2,14 @@ package budhttp
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/fs"
 	"net/http"
+	"strings"
 
 	"github.com/livebud/bud/package/js"
 	"github.com/livebud/bud/package/virtual"
@@ -161,13 +164,16 @@ type Eval struct {
 	Expr string
 }
 
+// StatusTerminated is returned by the dev server when an evaluation is
+// terminated. The body is the cause.
+const StatusTerminated = http.StatusServiceUnavailable
+
 func (c *client) Eval(path, expr string) (string, error) {
-	body, err := json.Marshal(Eval{path, expr})
-	if err != nil {
-		return "", err
-	}
-	url := c.baseURL + "/js/eval"
-	res, err := c.httpClient.Post(url, "application/json", bytes.NewReader(body))
+	return c.EvalContext(context.Background(), path, expr)
+}
+
+func (c *client) EvalContext(ctx context.Context, path, expr string) (string, error) {
+	res, err := c.post(ctx, "/js/eval", path, expr)
 	if err != nil {
 		return "", err
 	}
@@ -176,8 +182,80 @@ func (c *client) Eval(path, expr string) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	if res.StatusCode == StatusTerminated {
+		return "", &js.TerminatedError{Path: path, Cause: terminatedCause(string(resBody))}
+	}
 	if res.StatusCode != http.StatusOK {
 		return "", fmt.Errorf("budhttp: eval returned unexpected %d. %s", res.StatusCode, resBody)
 	}
 	return string(resBody), nil
 }
+
+// Trailers the dev server sets when a stream fails part way through
+const (
+	TrailerError      = "Bud-Error"
+	TrailerTerminated = "Bud-Terminated"
+)
+
+// Stream the expression on the dev server, copying chunks to w as they arrive
+func (c *client) Stream(ctx context.Context, path, expr string, w io.Writer) error {
+	res, err := c.post(ctx, "/js/stream", path, expr)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		resBody, err := io.ReadAll(res.Body)
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("budhttp: stream returned unexpected %d. %s", res.StatusCode, resBody)
+	}
+	if _, err := io.Copy(w, res.Body); err != nil {
+		if ctx.Err() != nil {
+			return &js.TerminatedError{Path: path, Cause: ctx.Err()}
+		}
+		return err
+	}
+	// Trailers are available once the body has been read
+	if cause := res.Trailer.Get(TrailerTerminated); cause != "" {
+		return &js.TerminatedError{Path: path, Cause: terminatedCause(cause)}
+	}
+	if message := res.Trailer.Get(TrailerError); message != "" {
+		return fmt.Errorf("budhttp: stream %q. %s", path, message)
+	}
+	return nil
+}
+
+// Post the expression to the dev server
+func (c *client) post(ctx context.Context, route, path, expr string) (*http.Response, error) {
+	body, err := json.Marshal(Eval{path, expr})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+route, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		// Cancelling the request terminates the evaluation on the dev server
+		if ctx.Err() != nil {
+			return nil, &js.TerminatedError{Path: path, Cause: ctx.Err()}
+		}
+		return nil, err
+	}
+	return res, nil
+}
+
+// Turn the cause sent by the dev server back into an error
+func terminatedCause(message string) error {
+	message = strings.TrimSpace(message)
+	for _, err := range []error{context.DeadlineExceeded, context.Canceled, js.ErrHeapLimit} {
+		if message == err.Error() {
+			return err
+		}
+	}
+	return errors.New(message)
+}
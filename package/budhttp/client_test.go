@@ -1,6 +1,7 @@
 package budhttp_test
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"io"
@@ -28,6 +29,7 @@ import (
 	"github.com/livebud/bud/package/budhttp"
 	"github.com/livebud/bud/package/budhttp/budsvr"
 	"github.com/livebud/bud/package/gomod"
+	"github.com/livebud/bud/package/js"
 	v8 "github.com/livebud/bud/package/js/v8"
 	"github.com/livebud/bud/package/svelte"
 )
@@ -219,3 +221,55 @@ func TestScriptEval(t *testing.T) {
 	is.NoErr(err)
 	is.Equal(val, "1")
 }
+
+func TestEvalContextTimeout(t *testing.T) {
+	ctx := context.Background()
+	is := is.New(t)
+	log := testlog.New()
+	dir := t.TempDir()
+	td := testdir.New(dir)
+	is.NoErr(td.Write(ctx))
+	ps := pubsub.New()
+	server, err := loadServer(ps, dir)
+	is.NoErr(err)
+	server.Start(ctx)
+	defer server.Close()
+	client, err := budhttp.Load(log, server.Address())
+	is.NoErr(err)
+	evalCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	_, err = client.EvalContext(evalCtx, "loop.js", "while (true) {}")
+	var terminated *js.TerminatedError
+	is.True(errors.As(err, &terminated))
+	is.Equal(terminated.Path, "loop.js")
+	// The dev server's VM is still usable
+	val, err := client.Eval("run.js", "1+1")
+	is.NoErr(err)
+	is.Equal(val, "2")
+}
+
+func TestStream(t *testing.T) {
+	ctx := context.Background()
+	is := is.New(t)
+	log := testlog.New()
+	dir := t.TempDir()
+	td := testdir.New(dir)
+	is.NoErr(td.Write(ctx))
+	ps := pubsub.New()
+	server, err := loadServer(ps, dir)
+	is.NoErr(err)
+	server.Start(ctx)
+	defer server.Close()
+	client, err := budhttp.Load(log, server.Address())
+	is.NoErr(err)
+	buf := new(bytes.Buffer)
+	err = client.Stream(ctx, "stream.js", `__budWrite__("a"); __budWrite__("b")`, buf)
+	is.NoErr(err)
+	is.Equal(buf.String(), "ab")
+	// Errors after the stream starts are sent back
+	buf.Reset()
+	err = client.Stream(ctx, "stream.js", `__budWrite__("a"); throw new Error("boom")`, buf)
+	is.True(err != nil)
+	is.In(err.Error(), "boom")
+	is.Equal(buf.String(), "a")
+}
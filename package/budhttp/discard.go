@@ -1,7 +1,9 @@
 package budhttp
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"io/fs"
 
 	"github.com/livebud/bud/framework/view/ssr"
@@ -25,6 +27,14 @@ func (discard) Eval(path, expression string) (string, error) {
 	return "", fmt.Errorf("budhttp: discard client does not support eval")
 }
 
+func (discard) EvalContext(ctx context.Context, path, expression string) (string, error) {
+	return "", fmt.Errorf("budhttp: discard client does not support eval")
+}
+
+func (discard) Stream(ctx context.Context, path, expression string, w io.Writer) error {
+	return fmt.Errorf("budhttp: discard client does not support stream")
+}
+
 func (discard) Open(name string) (fs.File, error) {
 	return nil, fmt.Errorf("budhttp: discard client does not support open")
 }
@@ -1,7 +1,48 @@
 package js
 
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
 // VM for evaluating javascript
 type VM interface {
 	Script(path, script string) error
 	Eval(path, expression string) (string, error)
+	// EvalContext evaluates the expression, terminating the evaluation when the
+	// context is cancelled
+	EvalContext(ctx context.Context, path, expression string) (string, error)
+	// Stream evaluates the expression, which writes chunks to w as they're
+	// produced by calling the global __budWrite__(chunk) function. Stream
+	// returns once the expression and any promise it returns have settled.
+	Stream(ctx context.Context, path, expression string, w io.Writer) error
+}
+
+const (
+	// DefaultTimeout is how long an evaluation can run before it's terminated
+	DefaultTimeout = 10 * time.Second
+	// DefaultHeapLimit is how large the heap can grow before an evaluation is
+	// terminated
+	DefaultHeapLimit = 512 << 20
+)
+
+// ErrHeapLimit is the cause of a termination when the heap grows too large
+var ErrHeapLimit = errors.New("js: heap limit exceeded")
+
+// TerminatedError is returned when an evaluation is terminated before it
+// finishes. The cause is the context's error or ErrHeapLimit.
+type TerminatedError struct {
+	Path  string
+	Cause error
+}
+
+func (e *TerminatedError) Error() string {
+	return fmt.Sprintf("js: terminated %q. %s", e.Path, e.Cause)
+}
+
+func (e *TerminatedError) Unwrap() error {
+	return e.Cause
 }
@@ -0,0 +1,220 @@
+package v8
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/livebud/bud/package/js"
+)
+
+// ErrPoolClosed is returned when evaluating on a closed pool
+var ErrPoolClosed = errors.New("v8: pool is closed")
+
+// NewPool creates a pool of up to size VMs that evaluate in parallel. VMs are
+// created as they're needed. They're replaced after an evaluation is terminated
+// or after maxEvals evaluations. When maxEvals is 0, healthy VMs are kept
+// around.
+func NewPool(size, maxEvals int) *Pool {
+	if size < 1 {
+		size = 1
+	}
+	return &Pool{
+		Timeout:   js.DefaultTimeout,
+		HeapLimit: js.DefaultHeapLimit,
+		maxEvals:  maxEvals,
+		slots:     make(chan struct{}, size),
+		idle:      make(chan *pooledVM, size),
+	}
+}
+
+// Pool of VMs. Scripts are loaded into each VM once, so evaluations don't
+// need to compile them again.
+type Pool struct {
+	// Timeout terminates evaluations that run longer. Zero disables the timeout.
+	Timeout time.Duration
+	// HeapLimit of each VM in bytes. Zero disables the limit.
+	HeapLimit uint64
+
+	maxEvals int
+	slots    chan struct{}  // Limits the number of VMs
+	idle     chan *pooledVM // VMs that are ready to evaluate
+
+	mu      sync.RWMutex // Guards scripts, version and closed
+	scripts []*script    // In the order they were first loaded
+	version int          // Incremented each time a script is loaded
+	closed  bool
+}
+
+var _ js.VM = (*Pool)(nil)
+
+type script struct {
+	path    string
+	code    string
+	version int
+}
+
+type pooledVM struct {
+	*VM
+	evals  int
+	loaded map[string]int // Version of each script loaded into the VM
+}
+
+// Script loads the script into every VM in the pool, including VMs that are
+// created later on. Loading a script with the same path again replaces it, so
+// VMs load the latest version on their next evaluation.
+func (p *Pool) Script(path, code string) error {
+	// Load the script into a VM first to report any errors
+	vm, err := p.acquire()
+	if err != nil {
+		return err
+	}
+	if err := vm.Script(path, code); err != nil {
+		p.release(vm, false)
+		return err
+	}
+	p.mu.Lock()
+	// Another script may have been loaded in the meantime, in which case the VM
+	// loaded the scripts out of order
+	inOrder := true
+	for _, script := range p.scripts {
+		if script.path != path && vm.loaded[script.path] != script.version {
+			inOrder = false
+			break
+		}
+	}
+	p.version++
+	p.setScript(&script{path, code, p.version})
+	vm.loaded[path] = p.version
+	p.mu.Unlock()
+	p.release(vm, inOrder)
+	return nil
+}
+
+// setScript adds the script or replaces the script with the same path
+func (p *Pool) setScript(s *script) {
+	for i, script := range p.scripts {
+		if script.path == s.path {
+			p.scripts[i] = s
+			return
+		}
+	}
+	p.scripts = append(p.scripts, s)
+}
+
+// Eval the expression on the next available VM
+func (p *Pool) Eval(path, expr string) (string, error) {
+	return p.EvalContext(context.Background(), path, expr)
+}
+
+// EvalContext evaluates the expression on the next available VM, terminating
+// it when the context is cancelled or the pool's limits are reached
+func (p *Pool) EvalContext(ctx context.Context, path, expr string) (result string, err error) {
+	if p.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.Timeout)
+		defer cancel()
+	}
+	vm, err := p.acquire()
+	if err != nil {
+		return "", err
+	}
+	defer func() { p.release(vm, healthy(err)) }()
+	vm.evals++
+	return vm.EvalContext(ctx, path, expr)
+}
+
+// Stream the expression on the next available VM, writing its chunks to w
+func (p *Pool) Stream(ctx context.Context, path, expr string, w io.Writer) (err error) {
+	if p.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.Timeout)
+		defer cancel()
+	}
+	vm, err := p.acquire()
+	if err != nil {
+		return err
+	}
+	defer func() { p.release(vm, healthy(err)) }()
+	vm.evals++
+	return vm.Stream(ctx, path, expr, w)
+}
+
+// Acquire a VM with all of the pool's scripts loaded
+func (p *Pool) acquire() (*pooledVM, error) {
+	p.slots <- struct{}{}
+	var vm *pooledVM
+	select {
+	case vm = <-p.idle:
+	default:
+		inner, err := Load()
+		if err != nil {
+			<-p.slots
+			return nil, err
+		}
+		inner.HeapLimit = p.HeapLimit
+		vm = &pooledVM{VM: inner, loaded: map[string]int{}}
+	}
+	p.mu.RLock()
+	closed := p.closed
+	var scripts []*script
+	for _, script := range p.scripts {
+		if vm.loaded[script.path] != script.version {
+			scripts = append(scripts, script)
+		}
+	}
+	p.mu.RUnlock()
+	if closed {
+		vm.Close()
+		<-p.slots
+		return nil, ErrPoolClosed
+	}
+	for _, script := range scripts {
+		if err := vm.Script(script.path, script.code); err != nil {
+			vm.Close()
+			<-p.slots
+			return nil, err
+		}
+		vm.loaded[script.path] = script.version
+	}
+	return vm, nil
+}
+
+// Healthy is false when the evaluation was terminated, because the VM may have
+// been stopped in the middle of changing its state. Errors thrown by the script
+// leave the VM usable.
+func healthy(err error) bool {
+	var terminated *js.TerminatedError
+	return !errors.As(err, &terminated)
+}
+
+// Release the VM back into the pool. VMs that failed may be in a bad state, so
+// they're closed and replaced by a new VM when it's needed.
+func (p *Pool) release(vm *pooledVM, healthy bool) {
+	defer func() { <-p.slots }()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed || !healthy || (p.maxEvals > 0 && vm.evals >= p.maxEvals) {
+		vm.Close()
+		return
+	}
+	// There are never more VMs than slots, so this doesn't block
+	p.idle <- vm
+}
+
+// Close the pool. VMs that are evaluating are closed once they're done.
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	for {
+		select {
+		case vm := <-p.idle:
+			vm.Close()
+		default:
+			return nil
+		}
+	}
+}
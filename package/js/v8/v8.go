@@ -1,7 +1,13 @@
 package v8
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
 	"os"
+	"sync"
+	"time"
 
 	"github.com/livebud/bud/package/js"
 	"go.kuoruan.net/v8go-polyfills/console"
@@ -22,9 +28,14 @@ func Eval(path, code string) (string, error) {
 	return vm.Eval(path, code)
 }
 
-func load() (*v8go.Isolate, *v8go.Context, error) {
+func load(write v8go.FunctionCallback) (*v8go.Isolate, *v8go.Context, error) {
 	isolate := v8go.NewIsolate()
 	global := v8go.NewObjectTemplate(isolate)
+	// Streaming support
+	if err := global.Set("__budWrite__", v8go.NewFunctionTemplate(isolate, write)); err != nil {
+		isolate.Dispose()
+		return nil, nil, err
+	}
 	// Fetch support
 	if err := fetch.InjectTo(isolate, global); err != nil {
 		isolate.TerminateExecution()
@@ -61,18 +72,19 @@ func load() (*v8go.Isolate, *v8go.Context, error) {
 }
 
 func Load() (*VM, error) {
-	isolate, context, err := load()
+	vm := &VM{HeapLimit: js.DefaultHeapLimit}
+	isolate, context, err := load(vm.write)
 	if err != nil {
 		return nil, err
 	}
-	return &VM{
-		isolate: isolate,
-		context: context,
-	}, nil
+	vm.isolate = isolate
+	vm.context = context
+	return vm, nil
 }
 
 func Compile(path, code string) (*VM, error) {
-	isolate, context, err := load()
+	vm := &VM{HeapLimit: js.DefaultHeapLimit}
+	isolate, context, err := load(vm.write)
 	if err != nil {
 		return nil, err
 	}
@@ -84,21 +96,31 @@ func Compile(path, code string) (*VM, error) {
 	if _, err := script.Run(context); err != nil {
 		return nil, err
 	}
-	return &VM{
-		isolate: isolate,
-		context: context,
-	}, nil
+	vm.isolate = isolate
+	vm.context = context
+	return vm, nil
 }
 
 type VM struct {
+	// HeapLimit fails evaluations that leave the isolate's heap larger than
+	// this many bytes. The heap is checked after running the script and while
+	// waiting on promises, so use a timeout to stop scripts that never return.
+	// Zero disables the limit.
+	HeapLimit uint64
+
+	mu      sync.Mutex // Isolates run one script at a time
 	isolate *v8go.Isolate
 	context *v8go.Context
+	w       io.Writer // Where __budWrite__ writes while streaming
+	werr    error     // First error writing to w
 }
 
 var _ js.VM = (*VM)(nil)
 
 // Compile a script into the context
 func (vm *VM) Script(path, code string) error {
+	vm.mu.Lock()
+	defer vm.mu.Unlock()
 	script, err := vm.isolate.CompileUnboundScript(code, path, v8go.CompileOptions{})
 	if err != nil {
 		return err
@@ -110,7 +132,72 @@ func (vm *VM) Script(path, code string) error {
 	return nil
 }
 
+// Eval the expression without a timeout
 func (vm *VM) Eval(path, expr string) (string, error) {
+	return vm.EvalContext(context.Background(), path, expr)
+}
+
+// EvalContext evaluates the expression, terminating it when the context is
+// cancelled
+func (vm *VM) EvalContext(ctx context.Context, path, expr string) (string, error) {
+	vm.mu.Lock()
+	defer vm.mu.Unlock()
+	return vm.run(ctx, path, expr)
+}
+
+// Stream evaluates the expression, writing the chunks passed to __budWrite__
+// to w as they're produced
+func (vm *VM) Stream(ctx context.Context, path, expr string, w io.Writer) error {
+	vm.mu.Lock()
+	defer vm.mu.Unlock()
+	vm.w, vm.werr = w, nil
+	defer func() { vm.w, vm.werr = nil, nil }()
+	if _, err := vm.run(ctx, path, expr); err != nil {
+		return err
+	}
+	return vm.werr
+}
+
+// Write a chunk to the stream. Writing outside of a stream is a no-op.
+func (vm *VM) write(info *v8go.FunctionCallbackInfo) *v8go.Value {
+	args := info.Args()
+	if vm.w == nil || vm.werr != nil || len(args) == 0 {
+		return nil
+	}
+	if _, err := io.WriteString(vm.w, args[0].String()); err != nil {
+		// Stop rendering since nobody is there to read it
+		vm.werr = err
+		message, _ := v8go.NewValue(vm.isolate, err.Error())
+		return vm.isolate.ThrowException(message)
+	}
+	return nil
+}
+
+func (vm *VM) run(ctx context.Context, path, expr string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", &js.TerminatedError{Path: path, Cause: err}
+	}
+	w := watch(ctx, vm.isolate)
+	result, err := vm.eval(path, expr, w)
+	if cause := w.stop(); cause != nil {
+		// The script may have finished right before it was terminated, leaving
+		// the termination pending. Running an empty script clears it, so the VM
+		// can be used again.
+		vm.context.RunScript("undefined", "terminate.js")
+		return "", &js.TerminatedError{Path: path, Cause: cause}
+	}
+	if errors.Is(err, js.ErrHeapLimit) || (err == nil && vm.overHeapLimit()) {
+		return "", &js.TerminatedError{Path: path, Cause: js.ErrHeapLimit}
+	}
+	return result, err
+}
+
+var errTerminated = errors.New("v8: terminated")
+
+// How long to wait between pumping the microtasks of a pending promise
+const pumpInterval = time.Millisecond
+
+func (vm *VM) eval(path, expr string, w *watchdog) (string, error) {
 	value, err := vm.context.RunScript(expr, path)
 	if err != nil {
 		return "", err
@@ -121,15 +208,88 @@ func (vm *VM) Eval(path, expr string) (string, error) {
 		if err != nil {
 			return "", err
 		}
-		// TODO: this could run forever
-		for prom.State() == v8go.Pending {
-			continue
+		// Pump the microtasks until the promise settles. Waiting in between
+		// releases the isolate, so timers and fetches can call back into it.
+		for {
+			vm.context.PerformMicrotaskCheckpoint()
+			if prom.State() != v8go.Pending {
+				break
+			}
+			if vm.overHeapLimit() {
+				return "", js.ErrHeapLimit
+			}
+			select {
+			case <-w.fired():
+				return "", errTerminated
+			case <-time.After(pumpInterval):
+			}
+		}
+		if prom.State() == v8go.Rejected {
+			return "", fmt.Errorf("v8: promise rejected in %q. %s", path, prom.Result().String())
 		}
 		return prom.Result().String(), nil
 	}
 	return value.String(), nil
 }
 
+// Check the heap between evaluations. This must be called from the goroutine
+// that's evaluating, because reading the heap statistics doesn't lock the
+// isolate.
+func (vm *VM) overHeapLimit() bool {
+	return vm.HeapLimit > 0 && vm.isolate.GetHeapStatistics().UsedHeapSize > vm.HeapLimit
+}
+
+// Watchdog terminates a running evaluation when its context is done.
+// TerminateExecution is the only call that's safe to make into the isolate
+// from another goroutine.
+type watchdog struct {
+	stopc  chan struct{}
+	done   chan struct{}
+	firedc chan struct{} // Closed after the evaluation is terminated
+	cause  error         // Set before firedc is closed
+}
+
+func watch(ctx context.Context, isolate *v8go.Isolate) *watchdog {
+	if ctx.Done() == nil {
+		return nil
+	}
+	w := &watchdog{
+		stopc:  make(chan struct{}),
+		done:   make(chan struct{}),
+		firedc: make(chan struct{}),
+	}
+	go func() {
+		defer close(w.done)
+		select {
+		case <-w.stopc:
+		case <-ctx.Done():
+			w.cause = ctx.Err()
+			isolate.TerminateExecution()
+			close(w.firedc)
+		}
+	}()
+	return w
+}
+
+// Fired returns a channel that's closed after the evaluation is terminated.
+// The channel of a nil watchdog is never closed.
+func (w *watchdog) fired() <-chan struct{} {
+	if w == nil {
+		return nil
+	}
+	return w.firedc
+}
+
+// Stop watching and return why the evaluation was terminated, if it was
+func (w *watchdog) stop() error {
+	if w == nil {
+		return nil
+	}
+	close(w.stopc)
+	<-w.done
+	return w.cause
+}
+
 func (vm *VM) Close() error {
 	vm.context.Close()
 	vm.isolate.TerminateExecution()
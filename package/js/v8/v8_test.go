@@ -1,9 +1,15 @@
 package v8_test
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/livebud/bud/internal/is"
+	"github.com/livebud/bud/package/js"
 	v8 "github.com/livebud/bud/package/js/v8"
 )
 
@@ -76,3 +82,220 @@ func TestSetClearTimeout(t *testing.T) {
 	is.NoErr(err)
 	is.Equal(res, "undefined")
 }
+
+func TestPool(t *testing.T) {
+	is := is.New(t)
+	pool := v8.NewPool(4, 0)
+	defer pool.Close()
+	is.NoErr(pool.Script("math.js", `var loads = (globalThis.loads || 0) + 1; const multiply = (a, b) => a * b`))
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := pool.Eval("run.js", "multiply(3, 2) + ':' + loads")
+			is.NoErr(err)
+			// Each VM only loads the script once
+			is.Equal("6:1", value)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestPoolReplaceScript(t *testing.T) {
+	is := is.New(t)
+	pool := v8.NewPool(2, 0)
+	defer pool.Close()
+	is.NoErr(pool.Script("version.js", `var loads = (globalThis.loads || 0) + 1; var version = 1`))
+	// Evaluate in parallel, so there may be more than one VM with the first version
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := pool.Eval("run.js", "version")
+			is.NoErr(err)
+			is.Equal("1", value)
+		}()
+	}
+	wg.Wait()
+	is.NoErr(pool.Script("version.js", `var loads = (globalThis.loads || 0) + 1; var version = 2`))
+	// Every VM loads the latest version, but only once
+	for i := 0; i < 4; i++ {
+		value, err := pool.Eval("run.js", "version + ':' + loads")
+		is.NoErr(err)
+		is.Equal("2:2", value)
+	}
+}
+
+func TestPoolMaxEvals(t *testing.T) {
+	is := is.New(t)
+	pool := v8.NewPool(1, 2)
+	defer pool.Close()
+	is.NoErr(pool.Script("count.js", `var count = 0`))
+	value, err := pool.Eval("run.js", "++count")
+	is.NoErr(err)
+	is.Equal("1", value)
+	value, err = pool.Eval("run.js", "++count")
+	is.NoErr(err)
+	is.Equal("2", value)
+	// The VM was replaced, so the script loads again
+	value, err = pool.Eval("run.js", "++count")
+	is.NoErr(err)
+	is.Equal("1", value)
+}
+
+func TestPoolError(t *testing.T) {
+	is := is.New(t)
+	pool := v8.NewPool(1, 0)
+	defer pool.Close()
+	is.NoErr(pool.Script("count.js", `var count = 0`))
+	value, err := pool.Eval("run.js", "++count")
+	is.NoErr(err)
+	is.Equal("1", value)
+	_, err = pool.Eval("run.js", `throw new Error("boom")`)
+	is.True(err != nil)
+	is.In(err.Error(), "boom")
+	// VMs are kept after errors thrown by the script
+	value, err = pool.Eval("run.js", "++count")
+	is.NoErr(err)
+	is.Equal("2", value)
+	_, err = pool.Eval("run.js", `(async () => { await null; throw new Error("boom") })()`)
+	is.True(err != nil)
+	is.In(err.Error(), "boom")
+	value, err = pool.Eval("run.js", "++count")
+	is.NoErr(err)
+	is.Equal("3", value)
+	// Scripts that fail aren't loaded into new VMs
+	err = pool.Script("bad.js", `var =`)
+	is.True(err != nil)
+	value, err = pool.Eval("run.js", "++count")
+	is.NoErr(err)
+	is.Equal("1", value)
+}
+
+func TestPoolClose(t *testing.T) {
+	is := is.New(t)
+	pool := v8.NewPool(2, 0)
+	value, err := pool.Eval("run.js", "1+1")
+	is.NoErr(err)
+	is.Equal("2", value)
+	is.NoErr(pool.Close())
+	_, err = pool.Eval("run.js", "1+1")
+	is.True(errors.Is(err, v8.ErrPoolClosed))
+}
+
+func TestEvalContextTimeout(t *testing.T) {
+	is := is.New(t)
+	vm, err := v8.Load()
+	is.NoErr(err)
+	defer vm.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = vm.EvalContext(ctx, "loop.js", "while (true) {}")
+	var terminated *js.TerminatedError
+	is.True(errors.As(err, &terminated))
+	is.Equal(terminated.Path, "loop.js")
+	is.True(errors.Is(err, context.DeadlineExceeded))
+	// The VM can be used after it's been terminated
+	value, err := vm.Eval("run.js", "1+1")
+	is.NoErr(err)
+	is.Equal("2", value)
+	// Promises that never settle are terminated too
+	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = vm.EvalContext(ctx, "wait.js", "new Promise(() => {})")
+	is.True(errors.As(err, &terminated))
+	is.True(errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestEvalContextCancelled(t *testing.T) {
+	is := is.New(t)
+	vm, err := v8.Load()
+	is.NoErr(err)
+	defer vm.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = vm.EvalContext(ctx, "run.js", "1+1")
+	is.True(errors.Is(err, context.Canceled))
+}
+
+func TestEvalHeapLimit(t *testing.T) {
+	is := is.New(t)
+	vm, err := v8.Load()
+	is.NoErr(err)
+	defer vm.Close()
+	vm.HeapLimit = 16 << 20
+	_, err = vm.Eval("grow.js", "var xs = []; for (let i = 0; i < 1e6; i++) { xs.push({ n: i, s: 'x' + i }) }; xs.length")
+	var terminated *js.TerminatedError
+	is.True(errors.As(err, &terminated))
+	is.True(errors.Is(err, js.ErrHeapLimit))
+	// Promises are checked while they're pending
+	vm.HeapLimit = 48 << 20
+	_, err = vm.Eval("grow.js", "(async () => { for (let i = 0; i < 1e6; i++) { xs.push({ n: i, s: 'x' + i }) }; await new Promise(() => {}) })()")
+	is.True(errors.As(err, &terminated))
+	is.True(errors.Is(err, js.ErrHeapLimit))
+}
+
+func TestPoolTimeout(t *testing.T) {
+	is := is.New(t)
+	pool := v8.NewPool(1, 0)
+	defer pool.Close()
+	pool.Timeout = 100 * time.Millisecond
+	is.NoErr(pool.Script("count.js", `var count = 0`))
+	value, err := pool.Eval("run.js", "++count")
+	is.NoErr(err)
+	is.Equal("1", value)
+	_, err = pool.Eval("loop.js", "while (true) {}")
+	is.True(errors.Is(err, context.DeadlineExceeded))
+	// Terminated VMs are replaced
+	value, err = pool.Eval("run.js", "++count")
+	is.NoErr(err)
+	is.Equal("1", value)
+}
+
+func TestStream(t *testing.T) {
+	is := is.New(t)
+	vm, err := v8.Load()
+	is.NoErr(err)
+	defer vm.Close()
+	is.NoErr(vm.Script("stream.js", `function stream(n) { for (let i = 0; i < n; i++) __budWrite__("chunk" + i + ";") }`))
+	buf := new(bytes.Buffer)
+	is.NoErr(vm.Stream(context.Background(), "run.js", "stream(3)", buf))
+	is.Equal(buf.String(), "chunk0;chunk1;chunk2;")
+	// Writing outside of a stream does nothing
+	value, err := vm.Eval("run.js", "stream(3); 'done'")
+	is.NoErr(err)
+	is.Equal(value, "done")
+}
+
+func TestStreamAsync(t *testing.T) {
+	is := is.New(t)
+	vm, err := v8.Load()
+	is.NoErr(err)
+	defer vm.Close()
+	buf := new(bytes.Buffer)
+	err = vm.Stream(context.Background(), "run.js", `(async () => { __budWrite__("a"); await null; __budWrite__("b") })()`, buf)
+	is.NoErr(err)
+	is.Equal(buf.String(), "ab")
+	// Rejected promises fail the stream
+	err = vm.Stream(context.Background(), "run.js", `(async () => { await null; throw new Error("boom") })()`, buf)
+	is.True(err != nil)
+	is.In(err.Error(), "boom")
+}
+
+type failWriter struct{}
+
+func (failWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("client went away")
+}
+
+func TestStreamWriteError(t *testing.T) {
+	is := is.New(t)
+	vm, err := v8.Load()
+	is.NoErr(err)
+	defer vm.Close()
+	err = vm.Stream(context.Background(), "run.js", `__budWrite__("a"); __budWrite__("b")`, failWriter{})
+	is.True(err != nil)
+	is.In(err.Error(), "client went away")
+}
@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"mime"
+	"net/http"
+)
+
+// Methods that don't need to be protected from cross-site request forgery
+var safeMethods = map[string]struct{}{
+	http.MethodGet:     {},
+	http.MethodHead:    {},
+	http.MethodOptions: {},
+	http.MethodTrace:   {},
+}
+
+const (
+	// CSRFCookie stores the token on the client
+	CSRFCookie = "_csrf"
+	// CSRFField is the hidden form field that holds the token
+	CSRFField = "_csrf"
+	// CSRFHeader holds the token for requests sent with fetch
+	CSRFHeader = "X-CSRF-Token"
+)
+
+type csrfKey struct{}
+
+// CSRF protects forms from cross-site request forgery using the double-submit
+// cookie pattern. Each client gets a random token in a cookie. Requests with
+// unsafe methods must send the same token back in the "_csrf" form field or the
+// "X-CSRF-Token" header. JSON requests are exempt because browsers won't send
+// them cross-site without CORS.
+//
+// Add CSRF after MethodOverride so the overridden method is checked.
+func CSRF() Middleware {
+	return Function(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token := ""
+			if cookie, err := r.Cookie(CSRFCookie); err == nil && validToken(cookie.Value) {
+				token = cookie.Value
+			}
+			if !isSafe(r.Method) && !isJSON(r) {
+				if token == "" || !sameToken(token, submittedToken(r)) {
+					http.Error(w, "middleware: invalid CSRF token", http.StatusForbidden)
+					return
+				}
+			}
+			// Issue a new token to clients without one
+			if token == "" {
+				token = newToken()
+				http.SetCookie(w, &http.Cookie{
+					Name:     CSRFCookie,
+					Value:    token,
+					Path:     "/",
+					HttpOnly: true,
+					Secure:   r.TLS != nil,
+					SameSite: http.SameSiteLaxMode,
+				})
+			}
+			ctx := context.WithValue(r.Context(), csrfKey{}, token)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	})
+}
+
+// CSRFToken returns the request's token for views to render into forms. The
+// token is empty when the CSRF middleware hasn't run.
+func CSRFToken(ctx context.Context) string {
+	token, _ := ctx.Value(csrfKey{}).(string)
+	return token
+}
+
+func isSafe(method string) bool {
+	_, ok := safeMethods[method]
+	return ok
+}
+
+func isJSON(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && mediaType == "application/json"
+}
+
+// Read the token from the header, falling back to the form body
+func submittedToken(r *http.Request) string {
+	if token := r.Header.Get(CSRFHeader); token != "" {
+		return token
+	}
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case formType:
+		if err := r.ParseForm(); err != nil {
+			return ""
+		}
+		return r.PostForm.Get(CSRFField)
+	case "multipart/form-data":
+		// Multipart bodies are only parsed when the token wasn't sent any other
+		// way. Wrap r.Body in http.MaxBytesReader beforehand to cap the upload.
+		if err := r.ParseMultipartForm(32 << 20); err != nil {
+			return ""
+		}
+		return r.PostForm.Get(CSRFField)
+	}
+	return ""
+}
+
+func sameToken(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+const tokenSize = 32
+
+func newToken() string {
+	token := make([]byte, tokenSize)
+	if _, err := rand.Read(token); err != nil {
+		panic("middleware: unable to generate a CSRF token. " + err.Error())
+	}
+	return base64.RawURLEncoding.EncodeToString(token)
+}
+
+func validToken(token string) bool {
+	decoded, err := base64.RawURLEncoding.DecodeString(token)
+	return err == nil && len(decoded) == tokenSize
+}
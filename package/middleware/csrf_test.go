@@ -0,0 +1,119 @@
+package middleware_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/livebud/bud/internal/is"
+	"github.com/livebud/bud/package/middleware"
+	"github.com/livebud/bud/package/router"
+)
+
+func csrfToken() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(middleware.CSRFToken(r.Context())))
+	})
+}
+
+// Get a token from a GET request
+func getToken(t testing.TB, handler http.Handler) *http.Cookie {
+	is := is.New(t)
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	res := w.Result()
+	is.Equal(res.StatusCode, 200)
+	cookies := res.Cookies()
+	is.Equal(len(cookies), 1)
+	is.Equal(cookies[0].Name, middleware.CSRFCookie)
+	is.True(cookies[0].HttpOnly)
+	is.Equal(w.Body.String(), cookies[0].Value)
+	return cookies[0]
+}
+
+func postForm(handler http.Handler, cookie *http.Cookie, values url.Values) *http.Response {
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(values.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w.Result()
+}
+
+func TestCSRFForm(t *testing.T) {
+	is := is.New(t)
+	handler := middleware.CSRF().Middleware(csrfToken())
+	cookie := getToken(t, handler)
+	values := url.Values{}
+	values.Set("_csrf", cookie.Value)
+	res := postForm(handler, cookie, values)
+	is.Equal(res.StatusCode, 200)
+	// The cookie is only set once
+	is.Equal(len(res.Cookies()), 0)
+}
+
+func TestCSRFMissing(t *testing.T) {
+	is := is.New(t)
+	handler := middleware.CSRF().Middleware(csrfToken())
+	cookie := getToken(t, handler)
+	res := postForm(handler, cookie, url.Values{})
+	is.Equal(res.StatusCode, 403)
+	// Without a cookie
+	values := url.Values{}
+	values.Set("_csrf", cookie.Value)
+	res = postForm(handler, nil, values)
+	is.Equal(res.StatusCode, 403)
+}
+
+func TestCSRFMismatch(t *testing.T) {
+	is := is.New(t)
+	handler := middleware.CSRF().Middleware(csrfToken())
+	cookie := getToken(t, handler)
+	other := getToken(t, handler)
+	values := url.Values{}
+	values.Set("_csrf", other.Value)
+	res := postForm(handler, cookie, values)
+	is.Equal(res.StatusCode, 403)
+}
+
+func TestCSRFHeader(t *testing.T) {
+	is := is.New(t)
+	handler := middleware.CSRF().Middleware(csrfToken())
+	cookie := getToken(t, handler)
+	req := httptest.NewRequest(http.MethodDelete, "/", nil)
+	req.Header.Set("X-CSRF-Token", cookie.Value)
+	req.AddCookie(cookie)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	is.Equal(w.Result().StatusCode, 200)
+}
+
+func TestCSRFJSONExempt(t *testing.T) {
+	is := is.New(t)
+	handler := middleware.CSRF().Middleware(csrfToken())
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(`{}`))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	is.Equal(w.Result().StatusCode, 200)
+}
+
+func TestCSRFMethodOverride(t *testing.T) {
+	is := is.New(t)
+	router := router.New()
+	router.Patch("/", ok())
+	handler := middleware.Compose(middleware.MethodOverride(), middleware.CSRF()).Middleware(router)
+	cookie := getToken(t, middleware.CSRF().Middleware(csrfToken()))
+	values := url.Values{}
+	values.Set("_method", http.MethodPatch)
+	res := postForm(handler, cookie, values)
+	is.Equal(res.StatusCode, 403)
+	values.Set("_csrf", cookie.Value)
+	res = postForm(handler, cookie, values)
+	is.Equal(res.StatusCode, 200)
+}
@@ -18,7 +18,7 @@ func ok() http.Handler {
 	})
 }
 
-func TestNoMethod404(t *testing.T) {
+func TestNoMethod405(t *testing.T) {
 	is := is.New(t)
 	values := url.Values{}
 	req, err := http.NewRequest(http.MethodPost, "/", bytes.NewBufferString(values.Encode()))
@@ -29,7 +29,7 @@ func TestNoMethod404(t *testing.T) {
 	router.Patch("/", ok())
 	middleware.MethodOverride().Middleware(router).ServeHTTP(w, req)
 	res := w.Result()
-	is.Equal(res.StatusCode, 404)
+	is.Equal(res.StatusCode, 405)
 }
 
 func TestPatch200(t *testing.T) {
@@ -47,7 +47,7 @@ func TestPatch200(t *testing.T) {
 	is.Equal(res.StatusCode, 200)
 }
 
-func TestPatchNoBody404(t *testing.T) {
+func TestPatchNoBody405(t *testing.T) {
 	is := is.New(t)
 	req, err := http.NewRequest(http.MethodPost, "/", nil)
 	is.NoErr(err)
@@ -57,10 +57,10 @@ func TestPatchNoBody404(t *testing.T) {
 	router.Patch("/", ok())
 	middleware.MethodOverride().Middleware(router).ServeHTTP(w, req)
 	res := w.Result()
-	is.Equal(res.StatusCode, 404)
+	is.Equal(res.StatusCode, 405)
 }
 
-func TestPatchNoType404(t *testing.T) {
+func TestPatchNoType405(t *testing.T) {
 	is := is.New(t)
 	values := url.Values{}
 	values.Set("_method", http.MethodPatch)
@@ -71,7 +71,7 @@ func TestPatchNoType404(t *testing.T) {
 	router.Patch("/", ok())
 	middleware.MethodOverride().Middleware(router).ServeHTTP(w, req)
 	res := w.Result()
-	is.Equal(res.StatusCode, 404)
+	is.Equal(res.StatusCode, 405)
 }
 
 func TestPatchInsensitive200(t *testing.T) {
@@ -119,7 +119,7 @@ func TestPut200(t *testing.T) {
 	is.Equal(res.StatusCode, 200)
 }
 
-func TestGet404(t *testing.T) {
+func TestGet405(t *testing.T) {
 	is := is.New(t)
 	values := url.Values{}
 	values.Set("_method", "get")
@@ -131,5 +131,5 @@ func TestGet404(t *testing.T) {
 	router.Get("/", ok())
 	middleware.MethodOverride().Middleware(router).ServeHTTP(w, req)
 	res := w.Result()
-	is.Equal(res.StatusCode, 404)
+	is.Equal(res.StatusCode, 405)
 }
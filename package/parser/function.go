@@ -32,6 +32,14 @@ func (fn *Function) Name() string {
 	return fn.node.Name.Name
 }
 
+// Doc returns the function's doc comment without the comment markers
+func (fn *Function) Doc() string {
+	if fn.node.Doc == nil {
+		return ""
+	}
+	return strings.TrimSpace(fn.node.Doc.Text())
+}
+
 // Receiver returns the receiver field, if any
 func (fn *Function) Receiver() *Receiver {
 	if fn.node.Recv == nil {
@@ -506,6 +506,34 @@ func (t *FuncType) node() ast.Expr {
 	return t.n
 }
 
+// Params of the function type
+func (t *FuncType) Params() []Type {
+	return fieldTypes(t.f, t.n.Params)
+}
+
+// Results of the function type
+func (t *FuncType) Results() []Type {
+	return fieldTypes(t.f, t.n.Results)
+}
+
+// Expand the field list into a type per field, including grouped fields like
+// (a, b int)
+func fieldTypes(f filer, list *ast.FieldList) (types []Type) {
+	if list == nil {
+		return nil
+	}
+	for _, field := range list.List {
+		n := len(field.Names)
+		if n == 0 {
+			n = 1
+		}
+		for i := 0; i < n; i++ {
+			types = append(types, getType(f, field.Type))
+		}
+	}
+	return types
+}
+
 // InterfaceType struct
 type InterfaceType struct {
 	f filer
@@ -560,6 +588,16 @@ func (t *MapType) node() ast.Expr {
 	return t.n
 }
 
+// Key type of the map
+func (t *MapType) Key() Type {
+	return getType(t.f, t.n.Key)
+}
+
+// Value type of the map
+func (t *MapType) Value() Type {
+	return getType(t.f, t.n.Value)
+}
+
 // ChanType struct
 type ChanType struct {
 	f filer
@@ -578,6 +616,16 @@ func (t *ChanType) node() ast.Expr {
 	return t.n
 }
 
+// Value type of the channel
+func (t *ChanType) Value() Type {
+	return getType(t.f, t.n.Value)
+}
+
+// Receive is true for receive-only channels (e.g. <-chan int)
+func (t *ChanType) Receive() bool {
+	return t.n.Dir == ast.RECV
+}
+
 // Ellipsis struct
 type EllipsisType struct {
 	f filer
@@ -0,0 +1,73 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/livebud/bud/package/middleware"
+)
+
+// Group routes under a prefix and wrap their handlers in middleware. Routes
+// added to the group are added to the router.
+func (rt *Router) Group(prefix string, stack ...middleware.Middleware) *Group {
+	return &Group{rt, groupPrefix(prefix), middleware.Compose(stack...)}
+}
+
+// Group of routes that share a prefix and middleware
+type Group struct {
+	router     *Router
+	prefix     string
+	middleware middleware.Middleware
+}
+
+func groupPrefix(prefix string) string {
+	prefix = trimTrailingSlash(prefix)
+	if prefix == "/" {
+		return ""
+	}
+	return prefix
+}
+
+// Add a handler to a route within the group
+func (g *Group) Add(method, route string, handler http.Handler) error {
+	return g.router.Add(method, join(g.prefix, route), g.middleware.Middleware(handler))
+}
+
+// Get route
+func (g *Group) Get(route string, handler http.Handler) error {
+	return g.Add(http.MethodGet, route, handler)
+}
+
+// Post route
+func (g *Group) Post(route string, handler http.Handler) error {
+	return g.Add(http.MethodPost, route, handler)
+}
+
+// Put route
+func (g *Group) Put(route string, handler http.Handler) error {
+	return g.Add(http.MethodPut, route, handler)
+}
+
+// Patch route
+func (g *Group) Patch(route string, handler http.Handler) error {
+	return g.Add(http.MethodPatch, route, handler)
+}
+
+// Delete route
+func (g *Group) Delete(route string, handler http.Handler) error {
+	return g.Add(http.MethodDelete, route, handler)
+}
+
+// Mount the handler within the group
+func (g *Group) Mount(prefix string, handler http.Handler) error {
+	return g.router.Mount(join(g.prefix, prefix), g.middleware.Middleware(handler))
+}
+
+// Group nests a group within this group. The outer group's middleware runs
+// first.
+func (g *Group) Group(prefix string, stack ...middleware.Middleware) *Group {
+	return &Group{
+		router:     g.router,
+		prefix:     join(g.prefix, groupPrefix(prefix)),
+		middleware: middleware.Compose(g.middleware, middleware.Compose(stack...)),
+	}
+}
@@ -0,0 +1,81 @@
+package router
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/livebud/bud/package/router/radix"
+)
+
+// Host returns the router for requests to the host. Hosts are matched without
+// the port and can have slots for labels (e.g. :tenant.example.com). Requests
+// that don't match any of the host's routes fall back to the router's routes.
+func (rt *Router) Host(pattern string) (*Router, error) {
+	pattern = strings.ToLower(pattern)
+	for _, host := range rt.hosts {
+		if host.pattern == pattern {
+			return host.router, nil
+		}
+	}
+	labels := strings.Split(pattern, ".")
+	for _, label := range labels {
+		if err := checkLabel(label); err != nil {
+			return nil, fmt.Errorf("router: invalid host %q. %w", pattern, err)
+		}
+	}
+	router := New()
+	rt.hosts = append(rt.hosts, &host{pattern, labels, router})
+	return router, nil
+}
+
+type host struct {
+	pattern string
+	labels  []string
+	router  *Router
+}
+
+// Match the request's host against the pattern
+func (h *host) match(requestHost string) (slots radix.Slots, ok bool) {
+	if hostname, _, err := net.SplitHostPort(requestHost); err == nil {
+		requestHost = hostname
+	}
+	labels := strings.Split(strings.ToLower(requestHost), ".")
+	if len(labels) != len(h.labels) {
+		return nil, false
+	}
+	for i, label := range h.labels {
+		if strings.HasPrefix(label, ":") {
+			slots = append(slots, &radix.Slot{Key: label[1:], Value: labels[i]})
+			continue
+		}
+		if label != labels[i] {
+			return nil, false
+		}
+	}
+	return slots, true
+}
+
+// Labels are either a name (e.g. api) or a slot (e.g. :tenant)
+func checkLabel(label string) error {
+	if label == "" {
+		return fmt.Errorf("empty label")
+	}
+	if slot := strings.TrimPrefix(label, ":"); slot != label {
+		for i, r := range slot {
+			if !(r >= 'a' && r <= 'z' || i > 0 && (r >= '0' && r <= '9' || r == '_')) {
+				return fmt.Errorf("invalid slot %q", label)
+			}
+		}
+		if slot == "" {
+			return fmt.Errorf("missing slot name after \":\"")
+		}
+		return nil
+	}
+	for _, r := range label {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-') {
+			return fmt.Errorf("invalid character %q", string(r))
+		}
+	}
+	return nil
+}
@@ -194,7 +194,31 @@ func lexSlotRest(l *lexer) stateFn {
 	if unicode.IsUpper(r) {
 		return l.errorf(`route %q: uppercase letters are not allowed %q`, l.input, string(r))
 	}
-	// After the slot name
+	// Support constraints like :id<int>
+	if r == '<' {
+		return lexConstraint
+	}
+	return lexSlotEnd(l, r)
+}
+
+// Constraints are between "<" and ">". They're either a named constraint like
+// int or uuid or a regular expression like [a-z]+.
+func lexConstraint(l *lexer) stateFn {
+	r := l.step()
+	if r == '>' {
+		return l.errorf(`route %q: empty constraint "<>"`, l.input)
+	}
+	for r != '>' {
+		if r == end {
+			return l.errorf(`route %q: missing ">" after constraint`, l.input)
+		}
+		r = l.step()
+	}
+	return lexSlotEnd(l, l.step())
+}
+
+// After the slot name and constraint
+func lexSlotEnd(l *lexer, r rune) stateFn {
 	switch r {
 	case '?':
 		// Support optional modifiers
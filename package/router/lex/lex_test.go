@@ -93,4 +93,35 @@ var tests = []struct {
 	{input: "/:sLot", err: `route "/:sLot": uppercase letters are not allowed "L"`},
 	{input: "/:sloT", err: `route "/:sloT": uppercase letters are not allowed "T"`},
 	{input: "/:sloT/", err: `route "/:sloT/": uppercase letters are not allowed "T"`},
+	// Constraints
+	{input: "/:id<int>", expect: `slash:"/" slot:":id<int>"`},
+	{input: "/users/:id<int>/edit", expect: `slash:"/" path:"users" slash:"/" slot:":id<int>" slash:"/" path:"edit"`},
+	{input: "/:slug<[a-z0-9-]+>", expect: `slash:"/" slot:":slug<[a-z0-9-]+>"`},
+	{input: "/:uuid<uuid>.:format?", expect: `slash:"/" slot:":uuid<uuid>" path:"." question:":format?"`},
+	{input: "/:id<int>?", expect: `slash:"/" question:":id<int>?"`},
+	{input: "/:path<[a-z]*>*", expect: `slash:"/" star:":path<[a-z]*>*"`},
+	{input: "/:id<>", err: `route "/:id<>": empty constraint "<>"`},
+	{input: "/:id<int", err: `route "/:id<int": missing ">" after constraint`},
+	{input: "/:id<int>x", err: `route "/:id<int>x": invalid slot character "x"`},
+	{input: "/:id<int>?/", err: `route "/:id<int>?/": optional "?" must be at the end`},
+}
+
+func TestSlot(t *testing.T) {
+	is := is.New(t)
+	tokens := []struct {
+		token      lex.Token
+		slot       string
+		constraint string
+	}{
+		{lex.Token{Type: lex.SlotToken, Value: ":id"}, "id", ""},
+		{lex.Token{Type: lex.QuestionToken, Value: ":id?"}, "id", ""},
+		{lex.Token{Type: lex.StarToken, Value: ":path*"}, "path", ""},
+		{lex.Token{Type: lex.SlotToken, Value: ":id<int>"}, "id", "int"},
+		{lex.Token{Type: lex.QuestionToken, Value: ":id<int>?"}, "id", "int"},
+		{lex.Token{Type: lex.StarToken, Value: ":path<[a-z]*>*"}, "path", "[a-z]*"},
+	}
+	for _, test := range tokens {
+		is.Equal(test.token.Slot(), test.slot)
+		is.Equal(test.token.Constraint(), test.constraint)
+	}
 }
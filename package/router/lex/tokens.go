@@ -33,6 +33,27 @@ func (t Token) String() string {
 	return fmt.Sprintf("%s:%q", t.Type, t.Value)
 }
 
+// Slot returns the slot name without the ":" prefix, the constraint or the
+// modifier. For example, ":id<int>?" returns "id".
+func (t Token) Slot() string {
+	name := strings.TrimPrefix(t.Value, ":")
+	if i := strings.IndexByte(name, '<'); i >= 0 {
+		return name[:i]
+	}
+	return strings.TrimRight(name, "?*")
+}
+
+// Constraint returns the slot's constraint or an empty string if the slot is
+// unconstrained. For example, ":id<int>?" returns "int".
+func (t Token) Constraint() string {
+	start := strings.IndexByte(t.Value, '<')
+	end := strings.LastIndexByte(t.Value, '>')
+	if start < 0 || end < start {
+		return ""
+	}
+	return t.Value[start+1 : end]
+}
+
 // Tokens is a list of tokens
 type Tokens []Token
 
@@ -0,0 +1,55 @@
+package router
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/livebud/bud/package/router/radix"
+)
+
+// Slot that holds the rest of the path within a mounted handler
+const mountSlot = "mount_path"
+
+// Mount the handler at the prefix for every method. The prefix can have slots
+// (e.g. /tenants/:tenant). Routes added to the router take priority over
+// mounted handlers.
+//
+// The request's URL is left alone, so handlers that expect the full path like
+// pprof work as-is. Wrap the handler in http.StripPrefix to strip the prefix.
+// Mounted routers match their routes against the path after the prefix.
+func (rt *Router) Mount(prefix string, handler http.Handler) error {
+	prefix = Normalize(prefix)
+	if prefix == "/" {
+		prefix = ""
+	}
+	return rt.mounts.Insert(prefix+"/:"+mountSlot+"*", handler)
+}
+
+// Prefix of the mounted route (e.g. /api/:mount_path* => /api)
+func mountPrefix(route string) string {
+	return strings.TrimSuffix(route, "/:"+mountSlot+"*")
+}
+
+// Serve the mounted handler with the rest of the path in the context
+func (rt *Router) serveMount(w http.ResponseWriter, r *http.Request, match *radix.Match) {
+	rest := "/"
+	var slots radix.Slots
+	for _, slot := range match.Slots {
+		if slot.Key == mountSlot {
+			rest += slot.Value
+			continue
+		}
+		slots = append(slots, slot)
+	}
+	r = withMatch(r, mountPrefix(match.Route), "/", slots)
+	r.Context().Value(contextKey{}).(*matched).path = rest
+	match.Handler.ServeHTTP(w, r)
+}
+
+// Path to route. Within a mounted router, this is the path after the prefix.
+func routePath(r *http.Request) string {
+	if m, ok := r.Context().Value(contextKey{}).(*matched); ok && m.path != "" {
+		return m.path
+	}
+	return r.URL.Path
+}
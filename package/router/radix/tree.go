@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"regexp"
 	"strings"
 
 	"github.com/livebud/bud/package/router/lex"
@@ -18,9 +19,16 @@ func New() Tree {
 type Tree interface {
 	Insert(route string, handler http.Handler) error
 	Match(path string) (*Match, bool)
+	Routes() []*Route
 	String() string
 }
 
+// Route is a route that was inserted into the tree
+type Route struct {
+	Route   string
+	Handler http.Handler
+}
+
 // Slots is a list of key value pairs
 type Slots []*Slot
 
@@ -37,6 +45,17 @@ type Match struct {
 	Slots   Slots
 }
 
+// AmbiguousError is returned when a route can't be told apart from a route
+// that's already in the tree (e.g. /:id and /:slug)
+type AmbiguousError struct {
+	Route    string // Route being inserted
+	Existing string // Route already in the tree
+}
+
+func (e *AmbiguousError) Error() string {
+	return fmt.Sprintf("radix: ambiguous routes %q and %q", e.Route, e.Existing)
+}
+
 // Match the path to a route
 type matchFn func(path string) (index int, slots Slots)
 
@@ -75,33 +94,64 @@ type tree struct {
 }
 
 func (t *tree) Insert(route string, handler http.Handler) error {
+	expanded, err := expand(route)
+	if err != nil {
+		return err
+	}
+	for _, tokens := range expanded {
+		if err := t.insert(tokens, route, handler); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Expand the route into the routes that Insert adds to the tree. Optional and
+// wildcard slots also add the route without the slot (e.g. /posts/:id? =>
+// /posts and /posts/:id).
+func Expand(route string) ([]string, error) {
+	expanded, err := expand(route)
+	if err != nil {
+		return nil, err
+	}
+	routes := make([]string, len(expanded))
+	for i, tokens := range expanded {
+		route := new(strings.Builder)
+		for _, token := range tokens {
+			route.WriteString(token.Value)
+		}
+		routes[i] = route.String()
+	}
+	return routes, nil
+}
+
+func expand(route string) (expanded []lex.Tokens, err error) {
 	lexer := lex.New(route)
 	var tokens lex.Tokens
 	for {
 		token := lexer.Next()
+		if err := checkConstraint(route, token); err != nil {
+			return nil, err
+		}
 		switch token.Type {
 		case lex.QuestionToken:
 			// Each optional tokens insert two routes
-			if err := t.insert(stripTokenTrail(tokens), route, handler); err != nil {
-				return err
-			}
+			expanded = append(expanded, stripTokenTrail(tokens))
 			// Make the optional token required
 			tokens = append(tokens, lex.Token{
-				Value: strings.TrimRight(token.Value, "?"),
+				Value: strings.TrimSuffix(token.Value, "?"),
 				Type:  lex.SlotToken,
 			})
 		case lex.StarToken:
 			// Each optional tokens insert two routes
-			if err := t.insert(stripTokenTrail(tokens), route, handler); err != nil {
-				return err
-			}
+			expanded = append(expanded, stripTokenTrail(tokens))
 			tokens = append(tokens, token)
 		case lex.ErrorToken:
 			// Error parsing the route
-			return errors.New(token.Value)
+			return nil, errors.New(token.Value)
 		case lex.EndToken:
 			// Done parsing the route
-			return t.insert(tokens, route, handler)
+			return append(expanded, tokens), nil
 		default:
 			tokens = append(tokens, token)
 		}
@@ -252,9 +302,17 @@ func insertWild(parent *node, child *node) error {
 			parent.wilds = append(parent.wilds[:i], append([]*node{child}, parent.wilds[i:]...)...)
 			return nil
 		}
-		// Don't allow /:id and /:hi on the same level.
 		if childp == wildp {
-			return fmt.Errorf("radix: ambiguous routes %q and %q", child.route, wild.route)
+			childc, wildc := child.tokens[0].Constraint(), wild.tokens[0].Constraint()
+			// Don't allow /:id and /:hi or /:id<int> and /:hi<int> on the same level.
+			if childc == wildc {
+				return &AmbiguousError{child.route, wild.route}
+			}
+			// Try constrained slots before unconstrained slots
+			if childc != "" && wildc == "" {
+				parent.wilds = append(parent.wilds[:i], append([]*node{child}, parent.wilds[i:]...)...)
+				return nil
+			}
 		}
 	}
 	parent.wilds = append(parent.wilds, child)
@@ -323,7 +381,8 @@ func matchExact(token lex.Token) matchFn {
 
 // Match a slot (/:id)
 func matchSlot(token lex.Token) matchFn {
-	slotKey := token.Value[1:]
+	slotKey := token.Slot()
+	valid := constraintOf(token)
 	return func(path string) (index int, slots Slots) {
 		lpath := len(path)
 		for i := 0; i < lpath; i++ {
@@ -332,7 +391,7 @@ func matchSlot(token lex.Token) matchFn {
 			}
 			index++
 		}
-		if index == 0 {
+		if index == 0 || !valid(path[:index]) {
 			return -1, nil
 		}
 		return index, Slots{{
@@ -344,9 +403,12 @@ func matchSlot(token lex.Token) matchFn {
 
 // Match a star (e.g. /:path*)
 func matchStar(token lex.Token) matchFn {
-	lvalue := len(token.Value)
-	slotKey := token.Value[1 : lvalue-1]
+	slotKey := token.Slot()
+	valid := constraintOf(token)
 	return func(path string) (index int, slots Slots) {
+		if !valid(path) {
+			return -1, nil
+		}
 		return len(path), Slots{{
 			Key:   slotKey,
 			Value: path,
@@ -354,6 +416,47 @@ func matchStar(token lex.Token) matchFn {
 	}
 }
 
+// Named constraints that can be used within slots (e.g. /:id<int>)
+var constraints = map[string]string{
+	"int":  `-?[0-9]+`,
+	"uuid": `[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`,
+}
+
+// Compile a constraint into an anchored regular expression. Constraints are
+// either named (e.g. int) or a regular expression (e.g. [a-z0-9-]+).
+func compileConstraint(constraint string) (*regexp.Regexp, error) {
+	if named, ok := constraints[constraint]; ok {
+		constraint = named
+	}
+	return regexp.Compile(`^(?:` + constraint + `)$`)
+}
+
+// Check that the token's constraint is valid
+func checkConstraint(route string, token lex.Token) error {
+	constraint := token.Constraint()
+	if constraint == "" {
+		return nil
+	}
+	if _, err := compileConstraint(constraint); err != nil {
+		return fmt.Errorf("radix: invalid constraint %q in route %q. %w", constraint, route, err)
+	}
+	return nil
+}
+
+// constraintOf returns a function that checks slot values against the token's
+// constraint. Constraints have already been checked during Insert.
+func constraintOf(token lex.Token) func(value string) bool {
+	constraint := token.Constraint()
+	if constraint == "" {
+		return func(string) bool { return true }
+	}
+	re, err := compileConstraint(constraint)
+	if err != nil {
+		return func(string) bool { return false }
+	}
+	return re.MatchString
+}
+
 // Match the node
 func (t *tree) match(node *node, path string, slots Slots) *Match {
 	index, matchSlots := node.match(path)
@@ -391,6 +494,33 @@ func (t *tree) match(node *node, path string, slots Slots) *Match {
 	return nil
 }
 
+// Routes returns the inserted routes in the order they're stored in the tree.
+// Routes with optional slots are only listed once.
+func (t *tree) Routes() (routes []*Route) {
+	seen := map[string]bool{}
+	t.walk(t.root, func(n *node) {
+		if n.handler == nil || seen[n.route] {
+			return
+		}
+		seen[n.route] = true
+		routes = append(routes, &Route{n.route, n.handler})
+	})
+	return routes
+}
+
+func (t *tree) walk(n *node, fn func(n *node)) {
+	if n == nil {
+		return
+	}
+	fn(n)
+	for _, child := range n.children {
+		t.walk(child, fn)
+	}
+	for _, wild := range n.wilds {
+		t.walk(wild, fn)
+	}
+}
+
 func (t *tree) String() string {
 	return t.string(t.root, "")
 }
@@ -1,6 +1,7 @@
 package radix_test
 
 import (
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -184,6 +185,17 @@ func TestAmbiguous(t *testing.T) {
 	})
 }
 
+func TestAmbiguousError(t *testing.T) {
+	is := is.New(t)
+	tree := radix.New()
+	is.NoErr(tree.Insert("/users/:id", handler("/users/:id")))
+	err := tree.Insert("/users/:slug", handler("/users/:slug"))
+	var ambiguous *radix.AmbiguousError
+	is.True(errors.As(err, &ambiguous))
+	is.Equal(ambiguous.Route, "/users/:slug")
+	is.Equal(ambiguous.Existing, "/users/:id")
+}
+
 func TestMatch(t *testing.T) {
 	ok(t, &test{
 		inserts: []*insert{
@@ -366,3 +378,77 @@ func TestNoRoutes(t *testing.T) {
 		},
 	})
 }
+
+func TestConstraints(t *testing.T) {
+	// Order shouldn't matter
+	okp(t, &test{
+		inserts: []*insert{
+			{route: "/posts/:id<int>"},
+			{route: "/posts/:uuid<uuid>"},
+			{route: "/posts/:slug"},
+			{route: "/posts/:id<int>/edit"},
+			{route: "/tags/:tag<[a-z0-9-]+>"},
+		},
+		requests: []*request{
+			{path: "/posts/10", route: "/posts/:id<int>", slots: `id=10`},
+			{path: "/posts/-10", route: "/posts/:id<int>", slots: `id=-10`},
+			{path: "/posts/0f8fad5b-d9cb-469f-a165-70867728950e", route: "/posts/:uuid<uuid>", slots: `uuid=0f8fad5b-d9cb-469f-a165-70867728950e`},
+			{path: "/posts/hello-world", route: "/posts/:slug", slots: `slug=hello-world`},
+			{path: "/posts/10/edit", route: "/posts/:id<int>/edit", slots: `id=10`},
+			{path: "/posts/hello-world/edit", nomatch: true},
+			{path: "/tags/go-1", route: "/tags/:tag<[a-z0-9-]+>", slots: `tag=go-1`},
+			{path: "/tags/go_1", nomatch: true},
+		},
+	})
+	ok(t, &test{
+		inserts: []*insert{
+			{route: "/:id<int>?"},
+			{route: "/files/:path<[a-z/]+>*"},
+		},
+		requests: []*request{
+			{path: "/", route: "/:id<int>?"},
+			{path: "/10", route: "/:id<int>?", slots: `id=10`},
+			{path: "/ten", nomatch: true},
+			{path: "/files/a/b", route: "/files/:path<[a-z/]+>*", slots: `path=a/b`},
+			{path: "/files/a/1", nomatch: true},
+		},
+	})
+	ok(t, &test{
+		inserts: []*insert{
+			{route: "/codes/:code<[A-Z]+>"},
+		},
+		requests: []*request{
+			{path: "/codes/ABC", route: "/codes/:code<[A-Z]+>", slots: `code=ABC`},
+			{path: "/codes/abc", nomatch: true},
+		},
+	})
+	ok(t, &test{
+		inserts: []*insert{
+			{route: "/:id<int>"},
+			{route: "/:num<int>", err: `radix: ambiguous routes "/:num<int>" and "/:id<int>"`},
+			{route: "/:slug<[a-z+>", err: "radix: invalid constraint \"[a-z+\" in route \"/:slug<[a-z+>\". error parsing regexp: missing closing ]: `[a-z+)$`"},
+		},
+		requests: []*request{
+			{path: "/10", route: "/:id<int>", slots: `id=10`},
+			{path: "/a", nomatch: true},
+		},
+	})
+}
+
+func TestExpand(t *testing.T) {
+	is := is.New(t)
+	routes, err := radix.Expand("/posts/:id<int>?")
+	is.NoErr(err)
+	is.Equal(routes, []string{"/posts", "/posts/:id<int>"})
+	routes, err = radix.Expand("/:owner/:repo/:path*")
+	is.NoErr(err)
+	is.Equal(routes, []string{"/:owner/:repo", "/:owner/:repo/:path*"})
+	routes, err = radix.Expand("/posts/:id.:format?")
+	is.NoErr(err)
+	is.Equal(routes, []string{"/posts/:id", "/posts/:id.:format"})
+	routes, err = radix.Expand("/users")
+	is.NoErr(err)
+	is.Equal(routes, []string{"/users"})
+	_, err = radix.Expand("/:id?/edit")
+	is.True(err != nil)
+}
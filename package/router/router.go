@@ -1,9 +1,13 @@
 package router
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"net/url"
+	"sort"
 	"strings"
+	"unicode"
 
 	"github.com/livebud/bud/package/router/radix"
 )
@@ -12,12 +16,15 @@ import (
 func New() *Router {
 	return &Router{
 		methods: map[string]radix.Tree{},
+		mounts:  radix.New(),
 	}
 }
 
 // Router struct
 type Router struct {
 	methods map[string]radix.Tree
+	mounts  radix.Tree // Mounted handlers for every method
+	hosts   []*host    // Routers for specific hosts
 }
 
 var _ http.Handler = (*Router)(nil)
@@ -31,12 +38,37 @@ func (rt *Router) Add(method, route string, handler http.Handler) error {
 }
 
 func (rt *Router) add(method, route string, handler http.Handler) error {
+	return rt.insert(method, Normalize(route), handler)
+}
+
+// Normalize the route the way the router stores it. The trailing slash is
+// trimmed and the route is lowercased, leaving slot constraints as they're
+// written.
+func Normalize(route string) string {
 	if route == "/" {
-		return rt.insert(method, route, handler)
+		return route
+	}
+	return strings.TrimRight(lowerRoute(route), "/")
+}
+
+// Lowercase the route, leaving slot constraints as they're written, since
+// regular expressions are case-sensitive (e.g. /Codes/:code<[A-Z]+> =>
+// /codes/:code<[A-Z]+>)
+func lowerRoute(route string) string {
+	lower := new(strings.Builder)
+	inConstraint := false
+	for _, r := range route {
+		switch {
+		case r == '<':
+			inConstraint = true
+		case r == '>':
+			inConstraint = false
+		case !inConstraint:
+			r = unicode.ToLower(r)
+		}
+		lower.WriteRune(r)
 	}
-	// Trim any trailing slash and lowercase the route
-	route = strings.TrimRight(strings.ToLower(route), "/")
-	return rt.insert(method, route, handler)
+	return lower.String()
 }
 
 // Insert the route into the method's radix tree
@@ -72,6 +104,50 @@ func (rt *Router) Delete(route string, handler http.Handler) error {
 	return rt.add(http.MethodDelete, route, handler)
 }
 
+// Route is a registered route
+type Route struct {
+	Method  string // HTTP method or "*" for mounted handlers
+	Route   string
+	Handler http.Handler
+	Host    string // Host pattern or empty for every host
+}
+
+// Routes returns the registered routes sorted by host, route, then method.
+// Routes of mounted routers are included with their prefix.
+func (rt *Router) Routes() (routes []*Route) {
+	routes = rt.routes("", "")
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Host != routes[j].Host {
+			return routes[i].Host < routes[j].Host
+		}
+		if routes[i].Route != routes[j].Route {
+			return routes[i].Route < routes[j].Route
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes
+}
+
+func (rt *Router) routes(hostPattern, prefix string) (routes []*Route) {
+	for method, tree := range rt.methods {
+		for _, route := range tree.Routes() {
+			routes = append(routes, &Route{method, join(prefix, route.Route), route.Handler, hostPattern})
+		}
+	}
+	for _, mount := range rt.mounts.Routes() {
+		mountPrefix := join(prefix, mountPrefix(mount.Route))
+		if router, ok := mount.Handler.(*Router); ok {
+			routes = append(routes, router.routes(hostPattern, mountPrefix)...)
+			continue
+		}
+		routes = append(routes, &Route{"*", join(mountPrefix, "/"), mount.Handler, hostPattern})
+	}
+	for _, host := range rt.hosts {
+		routes = append(routes, host.router.routes(host.pattern, prefix)...)
+	}
+	return routes
+}
+
 func (rt *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	handler := rt.Middleware(http.NotFoundHandler())
 	handler.ServeHTTP(w, r)
@@ -79,33 +155,149 @@ func (rt *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 // Middleware implements the router middleware
 func (rt *Router) Middleware(next http.Handler) http.Handler {
+	handler := rt.middleware(next)
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		tree, ok := rt.methods[r.Method]
-		if !ok {
-			next.ServeHTTP(w, r)
-			return
+		// Try the routers for the request's host first, falling back to the
+		// routes for every host
+		for _, host := range rt.hosts {
+			if slots, ok := host.match(r.Host); ok {
+				host.router.Middleware(handler).ServeHTTP(w, withMatch(r, "", "", slots))
+				return
+			}
 		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+func (rt *Router) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Strip any trailing slash (e.g. /users/ => /users)
-		urlPath := trimTrailingSlash(r.URL.Path)
+		urlPath := trimTrailingSlash(routePath(r))
 		// Match the path
-		match, ok := tree.Match(urlPath)
+		match, ok := rt.match(r.Method, urlPath)
 		if !ok {
-			next.ServeHTTP(w, r)
-			return
-		}
-		// Add the slots
-		if len(match.Slots) > 0 {
-			query := r.URL.Query()
-			for _, slot := range match.Slots {
-				query.Set(slot.Key, slot.Value)
+			// Check if the path is within a mounted handler
+			if mount, ok := rt.mounts.Match(urlPath); ok {
+				rt.serveMount(w, r, mount)
+				return
+			}
+			// Check if the path exists under another method
+			allow := rt.allow(urlPath)
+			switch {
+			case len(allow) == 0:
+				next.ServeHTTP(w, r)
+			case r.Method == http.MethodOptions:
+				w.Header().Set("Allow", strings.Join(allow, ", "))
+				w.WriteHeader(http.StatusNoContent)
+			default:
+				w.Header().Set("Allow", strings.Join(allow, ", "))
+				http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
 			}
-			r.URL.RawQuery = query.Encode()
+			return
 		}
-		// Call the handler
-		match.Handler.ServeHTTP(w, r)
+		// Call the handler with the route and slots in the context
+		match.Handler.ServeHTTP(w, withMatch(r, "", match.Route, match.Slots))
 	})
 }
 
+type contextKey struct{}
+
+// matched is the route that matched the request
+type matched struct {
+	prefix  string // Prefix of the mounted routers
+	path    string // Path after the prefix
+	pattern string
+	params  url.Values
+}
+
+// Store the matched route in the context, keeping the prefix and params of
+// the routers that the request passed through on the way here
+func withMatch(r *http.Request, prefix, route string, slots radix.Slots) *http.Request {
+	m := &matched{params: url.Values{}}
+	if outer, ok := r.Context().Value(contextKey{}).(*matched); ok {
+		m.prefix = outer.prefix
+		m.path = outer.path
+		m.pattern = outer.pattern
+		for key, values := range outer.params {
+			m.params[key] = append([]string{}, values...)
+		}
+	}
+	m.prefix = join(m.prefix, prefix)
+	if route != "" {
+		m.path = ""
+		m.pattern = join(m.prefix, route)
+	}
+	for _, slot := range slots {
+		m.params.Set(slot.Key, slot.Value)
+	}
+	return r.WithContext(context.WithValue(r.Context(), contextKey{}, m))
+}
+
+// Join the prefix and route (e.g. /api + /users => /api/users)
+func join(prefix, route string) string {
+	if prefix != "" && route == "/" {
+		return prefix
+	}
+	return prefix + route
+}
+
+// Params returns the slot values of the route that matched the request (e.g.
+// /users/:id => id=10). Params is empty when the request hasn't been routed.
+func Params(r *http.Request) url.Values {
+	if m, ok := r.Context().Value(contextKey{}).(*matched); ok {
+		return m.params
+	}
+	return url.Values{}
+}
+
+// Pattern returns the route that matched the request (e.g. /users/:id),
+// including the prefix of any mounted routers. Pattern is empty when the
+// request hasn't been routed.
+func Pattern(r *http.Request) string {
+	if m, ok := r.Context().Value(contextKey{}).(*matched); ok {
+		return m.pattern
+	}
+	return ""
+}
+
+// Match the path against the method's tree. HEAD requests fall back to the
+// GET handler.
+func (rt *Router) match(method, urlPath string) (*radix.Match, bool) {
+	if tree, ok := rt.methods[method]; ok {
+		if match, ok := tree.Match(urlPath); ok {
+			return match, true
+		}
+	}
+	if method == http.MethodHead {
+		return rt.match(http.MethodGet, urlPath)
+	}
+	return nil, false
+}
+
+// Allow returns the sorted methods that match the path, including the HEAD and
+// OPTIONS methods that the router answers automatically
+func (rt *Router) allow(urlPath string) (methods []string) {
+	seen := map[string]bool{}
+	for method, tree := range rt.methods {
+		if _, ok := tree.Match(urlPath); !ok {
+			continue
+		}
+		seen[method] = true
+		if method == http.MethodGet {
+			seen[http.MethodHead] = true
+		}
+	}
+	if len(seen) == 0 {
+		return nil
+	}
+	seen[http.MethodOptions] = true
+	for method := range seen {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	return methods
+}
+
 func trimTrailingSlash(path string) string {
 	if path == "/" {
 		return path
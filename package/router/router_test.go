@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/livebud/bud/internal/is"
+	"github.com/livebud/bud/package/middleware"
 	"github.com/livebud/bud/package/router"
 )
 
@@ -29,13 +30,14 @@ type request struct {
 	// response
 	status   int
 	location string
+	allow    string
 	body     string
 }
 
 // Handler returns the raw query
 func handler(route string) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Write([]byte(r.URL.RawQuery))
+		w.Write([]byte(router.Params(r).Encode()))
 	})
 }
 
@@ -83,6 +85,7 @@ func ok(t testing.TB, test *test) {
 			fmt.Println("location", url.Path)
 			is.Equal(request.location, url.Path)
 		}
+		is.Equal(request.allow, res.Header.Get("Allow"))
 		body, err := io.ReadAll(res.Body)
 		is.NoErr(err)
 		is.Equal(request.body, string(body))
@@ -151,21 +154,34 @@ func TestRouter(t *testing.T) {
 	})
 }
 
-func TestQueryPriority(t *testing.T) {
-	ok(t, &test{
-		routes: []*route{
-			{method: "GET", route: "/"},
-			{method: "GET", route: "/users/:id.:format?"},
-			{method: "GET", route: "/posts/:post_id/comments/:id.:format?"},
-		},
-		requests: []*request{
-			{method: "GET", path: "/?id=10", status: 200, body: "id=10"},
-			{method: "GET", path: `/users/10?id=20&format=bin&other=true`, status: 200, body: "format=bin&id=10&other=true"},
-			{method: "GET", path: `/users/10.json?id=20&format=bin&other=true`, status: 200, body: "format=json&id=10&other=true"},
-			{method: "GET", path: `/posts/1/comments/2?post_id=10&id=20&other=true`, status: 200, body: "id=2&other=true&post_id=1"},
-			{method: "GET", path: `/posts/1/comments/2.json?format=bin&post_id=10&id=20&other=true`, status: 200, body: "format=json&id=2&other=true&post_id=1"},
-		},
+func TestParams(t *testing.T) {
+	is := is.New(t)
+	rt := router.New()
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(router.Pattern(r) + " " + router.Params(r).Encode() + " " + r.URL.RawQuery))
 	})
+	rt.Get("/", handler)
+	rt.Get("/users/:id.:format?", handler)
+	rt.Get("/posts/:post_id/comments/:id.:format?", handler)
+	tests := []struct {
+		path   string
+		expect string
+	}{
+		{"/?id=10", "/  id=10"},
+		{"/users/10?id=20&format=bin&other=true", "/users/:id.:format? id=10 id=20&format=bin&other=true"},
+		{"/users/10.json?id=20", "/users/:id.:format? format=json&id=10 id=20"},
+		{"/posts/1/comments/2.json?post_id=10", "/posts/:post_id/comments/:id.:format? format=json&id=2&post_id=1 post_id=10"},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, test.path, nil)
+		rec := httptest.NewRecorder()
+		rt.ServeHTTP(rec, req)
+		is.Equal(rec.Body.String(), test.expect)
+	}
+	// Unrouted requests don't have params or a pattern
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	is.Equal(router.Pattern(req), "")
+	is.Equal(len(router.Params(req)), 0)
 }
 
 func TestTrailingSlash(t *testing.T) {
@@ -235,3 +251,215 @@ func TestAdd(t *testing.T) {
 	is.NoErr(err)
 	is.Equal("id=10", string(body))
 }
+
+func TestMethodNotAllowed(t *testing.T) {
+	ok(t, &test{
+		routes: []*route{
+			{method: "GET", route: "/users"},
+			{method: "POST", route: "/users"},
+			{method: "GET", route: "/users/:id"},
+			{method: "DELETE", route: "/users/:id"},
+			{method: "POST", route: "/sessions"},
+		},
+		requests: []*request{
+			{method: "PATCH", path: "/users", status: 405, allow: "GET, HEAD, OPTIONS, POST", body: "Method Not Allowed\n"},
+			{method: "POST", path: "/users/10", status: 405, allow: "DELETE, GET, HEAD, OPTIONS", body: "Method Not Allowed\n"},
+			{method: "GET", path: "/sessions", status: 405, allow: "OPTIONS, POST", body: "Method Not Allowed\n"},
+			{method: "PATCH", path: "/posts", status: 404, body: "404 page not found\n"},
+		},
+	})
+}
+
+func TestHead(t *testing.T) {
+	ok(t, &test{
+		routes: []*route{
+			{method: "GET", route: "/users/:id"},
+			{method: "POST", route: "/sessions"},
+		},
+		requests: []*request{
+			{method: "HEAD", path: "/users/10", status: 200, body: "id=10"},
+			{method: "HEAD", path: "/sessions", status: 405, allow: "OPTIONS, POST", body: "Method Not Allowed\n"},
+			{method: "HEAD", path: "/posts", status: 404, body: "404 page not found\n"},
+		},
+	})
+}
+
+func TestOptions(t *testing.T) {
+	ok(t, &test{
+		routes: []*route{
+			{method: "GET", route: "/users"},
+			{method: "POST", route: "/users"},
+			{method: "OPTIONS", route: "/custom"},
+		},
+		requests: []*request{
+			{method: "OPTIONS", path: "/users", status: 204, allow: "GET, HEAD, OPTIONS, POST"},
+			{method: "OPTIONS", path: "/users/", status: 204, allow: "GET, HEAD, OPTIONS, POST"},
+			{method: "OPTIONS", path: "/custom", status: 200},
+			{method: "OPTIONS", path: "/posts", status: 404, body: "404 page not found\n"},
+		},
+	})
+}
+
+func TestRoutes(t *testing.T) {
+	is := is.New(t)
+	router := router.New()
+	is.NoErr(router.Get("/users/:id.:format?", handler("/users/:id.:format?")))
+	is.NoErr(router.Get("/users", handler("/users")))
+	is.NoErr(router.Post("/users", handler("/users")))
+	is.NoErr(router.Get("/", handler("/")))
+	is.NoErr(router.Delete("/users/:id", handler("/users/:id")))
+	routes := router.Routes()
+	is.Equal(len(routes), 5)
+	is.Equal(routes[0].Method, "GET")
+	is.Equal(routes[0].Route, "/")
+	is.Equal(routes[1].Method, "GET")
+	is.Equal(routes[1].Route, "/users")
+	is.Equal(routes[2].Method, "POST")
+	is.Equal(routes[2].Route, "/users")
+	is.Equal(routes[3].Method, "DELETE")
+	is.Equal(routes[3].Route, "/users/:id")
+	is.Equal(routes[4].Method, "GET")
+	is.Equal(routes[4].Route, "/users/:id.:format?")
+	is.True(routes[4].Handler != nil)
+}
+
+func TestConstraints(t *testing.T) {
+	ok(t, &test{
+		routes: []*route{
+			{method: "GET", route: "/posts/:id<int>"},
+			{method: "GET", route: "/posts/:slug"},
+			{method: "DELETE", route: "/users/:id<int>"},
+		},
+		requests: []*request{
+			{method: "GET", path: "/posts/10", status: 200, body: "id=10"},
+			{method: "GET", path: "/posts/hello", status: 200, body: "slug=hello"},
+			{method: "DELETE", path: "/users/10", status: 200, body: "id=10"},
+			{method: "DELETE", path: "/users/ten", status: 404, body: "404 page not found\n"},
+		},
+	})
+	// Constraints keep their case, while the rest of the route is lowercased
+	ok(t, &test{
+		routes: []*route{
+			{method: "GET", route: "/Codes/:code<[A-Z]+>"},
+		},
+		requests: []*request{
+			{method: "GET", path: "/codes/ABC", status: 200, body: "code=ABC"},
+			{method: "GET", path: "/CODES/ABC", status: 200, body: "code=ABC"},
+			{method: "GET", path: "/codes/abc", status: 404, body: "404 page not found\n"},
+		},
+	})
+}
+
+// Serve the request and return the body
+func serve(handler http.Handler, method, target string) string {
+	req := httptest.NewRequest(method, target, nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec.Body.String()
+}
+
+// Write the text
+func text(s string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(s))
+	})
+}
+
+// Write the pattern, params and path
+func describe() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(router.Pattern(r) + " " + router.Params(r).Encode() + " " + r.URL.Path))
+	})
+}
+
+func TestMount(t *testing.T) {
+	is := is.New(t)
+	rt := router.New()
+	is.NoErr(rt.Get("/debug/vars", text("vars")))
+	is.NoErr(rt.Mount("/debug", describe()))
+	is.NoErr(rt.Mount("/tenants/:tenant", describe()))
+	is.Equal(serve(rt, "GET", "/debug/pprof/heap"), "/debug  /debug/pprof/heap")
+	is.Equal(serve(rt, "POST", "/debug"), "/debug  /debug")
+	is.Equal(serve(rt, "GET", "/tenants/acme/users"), "/tenants/:tenant tenant=acme /tenants/acme/users")
+	// Routes take priority
+	is.Equal(serve(rt, "GET", "/debug/vars"), "vars")
+	is.Equal(serve(rt, "GET", "/other"), "404 page not found\n")
+}
+
+func TestMountRouter(t *testing.T) {
+	is := is.New(t)
+	v1 := router.New()
+	is.NoErr(v1.Get("/", describe()))
+	is.NoErr(v1.Get("/users/:id", describe()))
+	rt := router.New()
+	is.NoErr(rt.Mount("/api/:version", v1))
+	is.Equal(serve(rt, "GET", "/api/v1"), "/api/:version version=v1 /api/v1")
+	is.Equal(serve(rt, "GET", "/api/v1/users/10?id=20"), "/api/:version/users/:id id=10&version=v1 /api/v1/users/10")
+	is.Equal(serve(rt, "GET", "/api/v1/posts"), "404 page not found\n")
+	routes := rt.Routes()
+	is.Equal(len(routes), 2)
+	is.Equal(routes[0].Route, "/api/:version")
+	is.Equal(routes[1].Route, "/api/:version/users/:id")
+}
+
+func TestGroup(t *testing.T) {
+	is := is.New(t)
+	header := func(value string) middleware.Middleware {
+		return middleware.Function(func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(value + " "))
+				next.ServeHTTP(w, r)
+			})
+		})
+	}
+	rt := router.New()
+	admin := rt.Group("/admin", header("auth"))
+	is.NoErr(admin.Get("/", describe()))
+	is.NoErr(admin.Post("/users/:id", describe()))
+	is.NoErr(admin.Group("/reports/", header("audit")).Get("/:year", describe()))
+	is.NoErr(rt.Get("/", describe()))
+	is.Equal(serve(rt, "GET", "/admin"), "auth /admin  /admin")
+	is.Equal(serve(rt, "POST", "/admin/users/10"), "auth /admin/users/:id id=10 /admin/users/10")
+	is.Equal(serve(rt, "GET", "/admin/reports/2022"), "auth audit /admin/reports/:year year=2022 /admin/reports/2022")
+	is.Equal(serve(rt, "GET", "/"), "/  /")
+}
+
+func TestHost(t *testing.T) {
+	is := is.New(t)
+	rt := router.New()
+	api, err := rt.Host("api.example.com")
+	is.NoErr(err)
+	is.NoErr(api.Get("/", text("api")))
+	tenant, err := rt.Host(":tenant.example.com")
+	is.NoErr(err)
+	is.NoErr(tenant.Get("/users/:id", describe()))
+	is.NoErr(rt.Get("/", text("www")))
+	is.NoErr(rt.Get("/health", text("health")))
+	same, err := rt.Host("API.example.com")
+	is.NoErr(err)
+	is.Equal(same, api)
+	is.Equal(serve(rt, "GET", "http://api.example.com/"), "api")
+	is.Equal(serve(rt, "GET", "http://www.example.com/"), "www")
+	// Falls back to the routes for every host
+	is.Equal(serve(rt, "GET", "http://api.example.com/health"), "health")
+	is.Equal(serve(rt, "GET", "http://acme.example.com:3000/users/10"), "/users/:id id=10&tenant=acme /users/10")
+	is.Equal(serve(rt, "GET", "http://example.com/users/10"), "404 page not found\n")
+	routes := rt.Routes()
+	is.Equal(len(routes), 4)
+	is.Equal(routes[0].Host, "")
+	is.Equal(routes[2].Host, ":tenant.example.com")
+	is.Equal(routes[3].Host, "api.example.com")
+	_, err = rt.Host("api..com")
+	is.True(err != nil)
+	is.Equal(err.Error(), `router: invalid host "api..com". empty label`)
+	_, err = rt.Host(":1.example.com")
+	is.True(err != nil)
+	is.Equal(err.Error(), `router: invalid host ":1.example.com". invalid slot ":1"`)
+}
+
+func TestNormalize(t *testing.T) {
+	is := is.New(t)
+	is.Equal(router.Normalize("/"), "/")
+	is.Equal(router.Normalize("/Users/"), "/users")
+	is.Equal(router.Normalize("/Codes/:Code<[A-Z]+>"), "/codes/:code<[A-Z]+>")
+}
@@ -0,0 +1,108 @@
+package session
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalid is returned when a cookie has been tampered with or was signed
+// with an unknown secret
+var ErrInvalid = errors.New("session: invalid cookie")
+
+// codec signs and optionally encrypts cookie values. The first secret encodes,
+// while every secret can decode to support rotating secrets.
+type codec struct {
+	keys []*keyPair
+}
+
+type keyPair struct {
+	sign    []byte
+	encrypt cipher.AEAD
+}
+
+func newCodec(secrets [][]byte) (*codec, error) {
+	if len(secrets) == 0 {
+		return nil, fmt.Errorf("session: at least one secret is required")
+	}
+	c := new(codec)
+	for _, secret := range secrets {
+		if len(secret) < 32 {
+			return nil, fmt.Errorf("session: secrets must be at least 32 bytes, got %d", len(secret))
+		}
+		// Derive separate keys for signing and encrypting
+		block, err := aes.NewCipher(derive(secret, "encrypt"))
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		c.keys = append(c.keys, &keyPair{derive(secret, "sign"), aead})
+	}
+	return c, nil
+}
+
+func derive(secret []byte, purpose string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("bud/session:" + purpose))
+	return mac.Sum(nil)
+}
+
+// Encode the value. The cookie name is part of the signature so values can't
+// be swapped between cookies.
+func (c *codec) Encode(name string, value []byte, encrypt bool) (string, error) {
+	key := c.keys[0]
+	if encrypt {
+		nonce := make([]byte, key.encrypt.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return "", err
+		}
+		value = key.encrypt.Seal(nonce, nonce, value, []byte(name))
+	}
+	payload := base64.RawURLEncoding.EncodeToString(value)
+	return payload + "." + sign(key.sign, name, payload), nil
+}
+
+// Decode the value, trying each of the secrets
+func (c *codec) Decode(name, encoded string, encrypted bool) ([]byte, error) {
+	payload, signature, ok := strings.Cut(encoded, ".")
+	if !ok {
+		return nil, ErrInvalid
+	}
+	value, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, ErrInvalid
+	}
+	for _, key := range c.keys {
+		if !hmac.Equal([]byte(signature), []byte(sign(key.sign, name, payload))) {
+			continue
+		}
+		if !encrypted {
+			return value, nil
+		}
+		size := key.encrypt.NonceSize()
+		if len(value) < size {
+			return nil, ErrInvalid
+		}
+		plain, err := key.encrypt.Open(nil, value[:size], value[size:], []byte(name))
+		if err != nil {
+			return nil, ErrInvalid
+		}
+		return plain, nil
+	}
+	return nil, ErrInvalid
+}
+
+func sign(key []byte, name, payload string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(name + "=" + payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
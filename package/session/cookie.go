@@ -0,0 +1,88 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ErrTooLarge is returned when the session doesn't fit in a cookie
+var ErrTooLarge = errors.New("session: too large to store in a cookie")
+
+// Browsers limit cookies to about 4KB
+const maxCookieSize = 4096
+
+// NewCookieStore stores the whole session in a signed cookie. Secrets should be
+// at least 32 random bytes. Pass older secrets after the first to rotate them.
+func NewCookieStore(secrets ...[]byte) (*CookieStore, error) {
+	codec, err := newCodec(secrets)
+	if err != nil {
+		return nil, err
+	}
+	return &CookieStore{
+		Cookie: DefaultCookie(),
+		codec:  codec,
+	}, nil
+}
+
+// CookieStore stores sessions in a signed cookie
+type CookieStore struct {
+	Cookie Cookie
+	// Encrypt the session so the client can't read it
+	Encrypt bool
+	codec   *codec
+}
+
+var _ Store = (*CookieStore)(nil)
+
+// Load the session from the cookie
+func (c *CookieStore) Load(r *http.Request) (*Session, error) {
+	cookie, err := r.Cookie(c.Cookie.Name)
+	if err != nil {
+		return New(), nil
+	}
+	value, err := c.codec.Decode(c.Cookie.Name, cookie.Value, c.Encrypt)
+	if err != nil {
+		// Start over when the cookie has been tampered with
+		return New(), nil
+	}
+	d := new(data)
+	if err := json.Unmarshal(value, d); err != nil {
+		return New(), nil
+	}
+	if d.Expires > 0 && time.Now().Unix() > d.Expires {
+		return New(), nil
+	}
+	return fromData(d.ID, d), nil
+}
+
+// Save the session to the cookie
+func (c *CookieStore) Save(w http.ResponseWriter, r *http.Request, session *Session) error {
+	if session.Destroyed() {
+		c.Cookie.set(w, r, "")
+		return nil
+	}
+	if !session.Modified() {
+		return nil
+	}
+	d := session.data()
+	d.ID = session.ID()
+	if expires := c.Cookie.expires(); !expires.IsZero() {
+		d.Expires = expires.Unix()
+	}
+	value, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+	encoded, err := c.codec.Encode(c.Cookie.Name, value, c.Encrypt)
+	if err != nil {
+		return err
+	}
+	if len(c.Cookie.Name)+len(encoded) > maxCookieSize {
+		return fmt.Errorf("%w (%d bytes)", ErrTooLarge, len(encoded))
+	}
+	c.Cookie.set(w, r, encoded)
+	return nil
+}
@@ -0,0 +1,104 @@
+package session
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/livebud/bud/package/middleware"
+)
+
+// ErrNotLoaded is returned when the session middleware hasn't run
+var ErrNotLoaded = errors.New("session: not loaded. Add session.Middleware to your middleware")
+
+type contextKey struct{}
+
+// Middleware loads the session into the request context and saves it right
+// before the response is written
+func Middleware(store Store) middleware.Middleware {
+	return middleware.Function(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			session, err := store.Load(r)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			r = r.WithContext(context.WithValue(r.Context(), contextKey{}, session))
+			sw := &responseWriter{ResponseWriter: w, r: r, store: store, session: session}
+			next.ServeHTTP(sw, r)
+			// Save the session if the handler didn't write anything
+			sw.save()
+		})
+	})
+}
+
+// From returns the session that the middleware loaded into the context
+func From(ctx context.Context) (*Session, error) {
+	session, ok := ctx.Value(contextKey{}).(*Session)
+	if !ok {
+		return nil, ErrNotLoaded
+	}
+	return session, nil
+}
+
+// responseWriter saves the session before the headers are written
+type responseWriter struct {
+	http.ResponseWriter
+	r       *http.Request
+	store   Store
+	session *Session
+	saved   bool
+	err     error
+}
+
+func (w *responseWriter) save() error {
+	if w.saved {
+		return w.err
+	}
+	w.saved = true
+	if w.err = w.store.Save(w.ResponseWriter, w.r, w.session); w.err != nil {
+		http.Error(w.ResponseWriter, w.err.Error(), http.StatusInternalServerError)
+	}
+	return w.err
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if err := w.save(); err != nil {
+		return
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if err := w.save(); err != nil {
+		return 0, err
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush supports streaming responses
+func (w *responseWriter) Flush() {
+	if err := w.save(); err != nil {
+		return
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack supports upgrading the connection
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("session: %T doesn't support hijacking", w.ResponseWriter)
+	}
+	return hijacker.Hijack()
+}
+
+// Unwrap the response writer
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,153 @@
+package session
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"sort"
+)
+
+// New session with a random ID
+func New() *Session {
+	return &Session{
+		id:      newID(),
+		values:  map[string]string{},
+		flashes: map[string][]string{},
+		isNew:   true,
+	}
+}
+
+// Session holds the state of a single visitor across requests
+type Session struct {
+	id        string
+	storedID  string // ID the store loaded the session with
+	values    map[string]string
+	flashes   map[string][]string
+	isNew     bool
+	modified  bool
+	destroyed bool
+}
+
+// ID of the session
+func (s *Session) ID() string {
+	return s.id
+}
+
+// Get a value from the session
+func (s *Session) Get(key string) string {
+	return s.values[key]
+}
+
+// Has checks if the session has a value
+func (s *Session) Has(key string) bool {
+	_, ok := s.values[key]
+	return ok
+}
+
+// Set a value in the session
+func (s *Session) Set(key, value string) {
+	s.values[key] = value
+	s.modified = true
+}
+
+// Delete a value from the session
+func (s *Session) Delete(key string) {
+	if _, ok := s.values[key]; !ok {
+		return
+	}
+	delete(s.values, key)
+	s.modified = true
+}
+
+// Keys in the session in sorted order
+func (s *Session) Keys() []string {
+	keys := make([]string, 0, len(s.values))
+	for key := range s.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Flash a message for the next request. Flash messages survive exactly one
+// redirect because they're removed as they're read.
+func (s *Session) Flash(key, message string) {
+	s.flashes[key] = append(s.flashes[key], message)
+	s.modified = true
+}
+
+// Flashes returns and removes the flash messages
+func (s *Session) Flashes(key string) []string {
+	messages, ok := s.flashes[key]
+	if !ok {
+		return nil
+	}
+	delete(s.flashes, key)
+	s.modified = true
+	return messages
+}
+
+// Renew the session ID while keeping its values. Renew after logging in to
+// prevent session fixation.
+func (s *Session) Renew() {
+	s.id = newID()
+	s.modified = true
+}
+
+// Destroy the session. The store removes the session when it's saved.
+func (s *Session) Destroy() {
+	s.values = map[string]string{}
+	s.flashes = map[string][]string{}
+	s.destroyed = true
+	s.modified = true
+}
+
+// IsNew is true if the session wasn't loaded from the request
+func (s *Session) IsNew() bool {
+	return s.isNew
+}
+
+// Modified is true if the session needs to be saved
+func (s *Session) Modified() bool {
+	return s.modified
+}
+
+// Destroyed is true if the session has been destroyed
+func (s *Session) Destroyed() bool {
+	return s.destroyed
+}
+
+// data is how sessions are encoded in stores
+type data struct {
+	ID      string              `json:"id,omitempty"`
+	Values  map[string]string   `json:"values,omitempty"`
+	Flashes map[string][]string `json:"flashes,omitempty"`
+	Expires int64               `json:"expires,omitempty"` // Unix time
+}
+
+func (s *Session) data() *data {
+	return &data{Values: s.values, Flashes: s.flashes}
+}
+
+func fromData(id string, d *data) *Session {
+	session := &Session{
+		id:      id,
+		values:  d.Values,
+		flashes: d.Flashes,
+	}
+	if session.values == nil {
+		session.values = map[string]string{}
+	}
+	if session.flashes == nil {
+		session.flashes = map[string][]string{}
+	}
+	return session
+}
+
+// newID returns a random 256-bit session ID
+func newID() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic("session: unable to generate a random id. " + err.Error())
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,200 @@
+package session_test
+
+import (
+	"bytes"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/livebud/bud/internal/is"
+	"github.com/livebud/bud/package/session"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+var secret = bytes.Repeat([]byte("s"), 32)
+var secret2 = bytes.Repeat([]byte("t"), 32)
+
+// Send a request with the cookies from the previous response
+func request(handler http.Handler, method, path string, cookies []*http.Cookie) *http.Response {
+	req := httptest.NewRequest(method, path, nil)
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec.Result()
+}
+
+func counter(store session.Store) http.Handler {
+	return session.Middleware(store).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sess, err := session.From(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		sess.Set("count", sess.Get("count")+"1")
+		w.Write([]byte(sess.Get("count")))
+	}))
+}
+
+func body(res *http.Response) string {
+	buf := new(bytes.Buffer)
+	buf.ReadFrom(res.Body)
+	return buf.String()
+}
+
+func TestCookieStore(t *testing.T) {
+	is := is.New(t)
+	store, err := session.NewCookieStore(secret)
+	is.NoErr(err)
+	handler := counter(store)
+	res := request(handler, "GET", "/", nil)
+	is.Equal(body(res), "1")
+	cookies := res.Cookies()
+	is.Equal(len(cookies), 1)
+	is.Equal(cookies[0].Name, "bud_session")
+	is.True(cookies[0].HttpOnly)
+	res = request(handler, "GET", "/", cookies)
+	is.Equal(body(res), "11")
+	res = request(handler, "GET", "/", res.Cookies())
+	is.Equal(body(res), "111")
+}
+
+func TestCookieStoreTampered(t *testing.T) {
+	is := is.New(t)
+	store, err := session.NewCookieStore(secret)
+	is.NoErr(err)
+	handler := counter(store)
+	res := request(handler, "GET", "/", nil)
+	is.Equal(body(res), "1")
+	cookie := res.Cookies()[0]
+	cookie.Value = "x" + cookie.Value
+	res = request(handler, "GET", "/", []*http.Cookie{cookie})
+	is.Equal(body(res), "1")
+}
+
+func TestCookieStoreEncrypt(t *testing.T) {
+	is := is.New(t)
+	store, err := session.NewCookieStore(secret)
+	is.NoErr(err)
+	store.Encrypt = true
+	handler := session.Middleware(store).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sess, err := session.From(r.Context())
+		is.NoErr(err)
+		if r.URL.Path == "/set" {
+			sess.Set("user", "alice")
+			return
+		}
+		w.Write([]byte(sess.Get("user")))
+	}))
+	res := request(handler, "GET", "/set", nil)
+	cookies := res.Cookies()
+	is.Equal(len(cookies), 1)
+	is.True(!strings.Contains(cookies[0].Value, "alice"))
+	res = request(handler, "GET", "/", cookies)
+	is.Equal(body(res), "alice")
+}
+
+func TestCookieStoreRotate(t *testing.T) {
+	is := is.New(t)
+	oldStore, err := session.NewCookieStore(secret)
+	is.NoErr(err)
+	res := request(counter(oldStore), "GET", "/", nil)
+	is.Equal(body(res), "1")
+	newStore, err := session.NewCookieStore(secret2, secret)
+	is.NoErr(err)
+	res = request(counter(newStore), "GET", "/", res.Cookies())
+	is.Equal(body(res), "11")
+}
+
+func TestShortSecret(t *testing.T) {
+	is := is.New(t)
+	_, err := session.NewCookieStore([]byte("short"))
+	is.True(err != nil)
+	is.In(err.Error(), "at least 32 bytes")
+}
+
+func TestFlash(t *testing.T) {
+	is := is.New(t)
+	store, err := session.NewCookieStore(secret)
+	is.NoErr(err)
+	handler := session.Middleware(store).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sess, err := session.From(r.Context())
+		is.NoErr(err)
+		if r.Method == http.MethodPost {
+			sess.Flash("notice", "saved")
+			http.Redirect(w, r, "/", http.StatusSeeOther)
+			return
+		}
+		w.Write([]byte(strings.Join(sess.Flashes("notice"), ",")))
+	}))
+	res := request(handler, "POST", "/", nil)
+	is.Equal(res.StatusCode, http.StatusSeeOther)
+	// The flash is shown after the redirect
+	res = request(handler, "GET", "/", res.Cookies())
+	is.Equal(body(res), "saved")
+	// Then it's gone
+	res = request(handler, "GET", "/", res.Cookies())
+	is.Equal(body(res), "")
+}
+
+func TestDestroy(t *testing.T) {
+	is := is.New(t)
+	store, err := session.NewCookieStore(secret)
+	is.NoErr(err)
+	res := request(counter(store), "GET", "/", nil)
+	is.Equal(body(res), "1")
+	handler := session.Middleware(store).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sess, err := session.From(r.Context())
+		is.NoErr(err)
+		sess.Destroy()
+	}))
+	res = request(handler, "GET", "/", res.Cookies())
+	cookies := res.Cookies()
+	is.Equal(len(cookies), 1)
+	is.Equal(cookies[0].MaxAge, -1)
+}
+
+func TestNotLoaded(t *testing.T) {
+	is := is.New(t)
+	req := httptest.NewRequest("GET", "/", nil)
+	sess, err := session.From(req.Context())
+	is.Equal(sess, nil)
+	is.Equal(err, session.ErrNotLoaded)
+}
+
+func TestSQLiteStore(t *testing.T) {
+	is := is.New(t)
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "sessions.db"))
+	is.NoErr(err)
+	defer db.Close()
+	store, err := session.NewSQLiteStore(db, secret)
+	is.NoErr(err)
+	handler := counter(store)
+	res := request(handler, "GET", "/", nil)
+	is.Equal(body(res), "1")
+	cookies := res.Cookies()
+	is.Equal(len(cookies), 1)
+	res = request(handler, "GET", "/", cookies)
+	is.Equal(body(res), "11")
+	var count int
+	is.NoErr(db.QueryRow(`SELECT COUNT(*) FROM sessions`).Scan(&count))
+	is.Equal(count, 1)
+	// Renewing the session replaces the stored session
+	renew := session.Middleware(store).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sess, err := session.From(r.Context())
+		is.NoErr(err)
+		sess.Renew()
+		w.Write([]byte(sess.Get("count")))
+	}))
+	res = request(renew, "GET", "/", res.Cookies())
+	is.Equal(body(res), "11")
+	is.NoErr(db.QueryRow(`SELECT COUNT(*) FROM sessions`).Scan(&count))
+	is.Equal(count, 1)
+	res = request(handler, "GET", "/", res.Cookies())
+	is.Equal(body(res), "111")
+	is.NoErr(store.Cleanup())
+}
@@ -0,0 +1,125 @@
+package session
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// NewSQLiteStore stores sessions in a SQLite table and only keeps the signed
+// session ID in the cookie. The sessions table is created if it doesn't exist.
+// The database should be opened with the go-sqlite3 driver:
+//
+//	import _ "github.com/mattn/go-sqlite3"
+//	db, err := sql.Open("sqlite3", "sessions.db")
+func NewSQLiteStore(db *sql.DB, secrets ...[]byte) (*SQLiteStore, error) {
+	codec, err := newCodec(secrets)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS sessions (
+			id TEXT PRIMARY KEY,
+			data BLOB NOT NULL,
+			expires_at INTEGER NOT NULL
+		)
+	`); err != nil {
+		return nil, fmt.Errorf("session: unable to create the sessions table. %w", err)
+	}
+	return &SQLiteStore{
+		Cookie: DefaultCookie(),
+		db:     db,
+		codec:  codec,
+	}, nil
+}
+
+// SQLiteStore stores sessions in SQLite
+type SQLiteStore struct {
+	Cookie Cookie
+	db     *sql.DB
+	codec  *codec
+}
+
+var _ Store = (*SQLiteStore)(nil)
+
+// Load the session by the ID in the cookie
+func (s *SQLiteStore) Load(r *http.Request) (*Session, error) {
+	cookie, err := r.Cookie(s.Cookie.Name)
+	if err != nil {
+		return New(), nil
+	}
+	id, err := s.codec.Decode(s.Cookie.Name, cookie.Value, false)
+	if err != nil {
+		return New(), nil
+	}
+	var value []byte
+	var expiresAt int64
+	row := s.db.QueryRowContext(r.Context(), `SELECT data, expires_at FROM sessions WHERE id = ?`, string(id))
+	if err := row.Scan(&value, &expiresAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return New(), nil
+		}
+		return nil, fmt.Errorf("session: unable to load. %w", err)
+	}
+	if expiresAt > 0 && time.Now().Unix() > expiresAt {
+		return New(), nil
+	}
+	d := new(data)
+	if err := json.Unmarshal(value, d); err != nil {
+		return nil, fmt.Errorf("session: unable to decode. %w", err)
+	}
+	session := fromData(string(id), d)
+	// Remember the stored ID in case the session is renewed
+	session.storedID = session.id
+	return session, nil
+}
+
+// Save the session to the database
+func (s *SQLiteStore) Save(w http.ResponseWriter, r *http.Request, session *Session) error {
+	ctx := r.Context()
+	// Remove the old session when it's been destroyed or renewed
+	if session.storedID != "" && (session.Destroyed() || session.storedID != session.ID()) {
+		if _, err := s.db.ExecContext(ctx, `DELETE FROM sessions WHERE id = ?`, session.storedID); err != nil {
+			return fmt.Errorf("session: unable to delete. %w", err)
+		}
+	}
+	if session.Destroyed() {
+		s.Cookie.set(w, r, "")
+		return nil
+	}
+	if !session.Modified() {
+		return nil
+	}
+	value, err := json.Marshal(session.data())
+	if err != nil {
+		return err
+	}
+	var expiresAt int64
+	if expires := s.Cookie.expires(); !expires.IsZero() {
+		expiresAt = expires.Unix()
+	}
+	if _, err := s.db.ExecContext(ctx, `
+		INSERT INTO sessions (id, data, expires_at) VALUES (?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET data = excluded.data, expires_at = excluded.expires_at
+	`, session.ID(), value, expiresAt); err != nil {
+		return fmt.Errorf("session: unable to save. %w", err)
+	}
+	session.storedID = session.ID()
+	encoded, err := s.codec.Encode(s.Cookie.Name, []byte(session.ID()), false)
+	if err != nil {
+		return err
+	}
+	s.Cookie.set(w, r, encoded)
+	return nil
+}
+
+// Cleanup removes the expired sessions
+func (s *SQLiteStore) Cleanup() error {
+	if _, err := s.db.Exec(`DELETE FROM sessions WHERE expires_at > 0 AND expires_at < ?`, time.Now().Unix()); err != nil {
+		return fmt.Errorf("session: unable to cleanup. %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package session
+
+import (
+	"net/http"
+	"time"
+)
+
+// Store loads and saves sessions
+type Store interface {
+	// Load the session from the request. Requests without a valid session get a
+	// new one.
+	Load(r *http.Request) (*Session, error)
+	// Save the session, setting the cookie on the response
+	Save(w http.ResponseWriter, r *http.Request, session *Session) error
+}
+
+// Cookie configures the session cookie
+type Cookie struct {
+	Name     string
+	Path     string
+	Domain   string
+	MaxAge   time.Duration
+	SameSite http.SameSite
+	// Secure cookies are only sent over HTTPS. Cookies are always secure when
+	// the request came in over TLS.
+	Secure bool
+}
+
+// DefaultCookie expires after 30 days
+func DefaultCookie() Cookie {
+	return Cookie{
+		Name:     "bud_session",
+		Path:     "/",
+		MaxAge:   30 * 24 * time.Hour,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+// Set the cookie's value on the response. An empty value deletes the cookie.
+func (c *Cookie) set(w http.ResponseWriter, r *http.Request, value string) {
+	cookie := &http.Cookie{
+		Name:     c.Name,
+		Value:    value,
+		Path:     c.Path,
+		Domain:   c.Domain,
+		SameSite: c.SameSite,
+		Secure:   c.Secure || r.TLS != nil,
+		HttpOnly: true,
+	}
+	switch {
+	case value == "":
+		cookie.MaxAge = -1
+		cookie.Expires = time.Unix(1, 0)
+	case c.MaxAge > 0:
+		cookie.MaxAge = int(c.MaxAge.Seconds())
+		cookie.Expires = time.Now().Add(c.MaxAge)
+	}
+	http.SetCookie(w, cookie)
+}
+
+// Expires returns when a session saved now will expire
+func (c *Cookie) expires() time.Time {
+	if c.MaxAge <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(c.MaxAge)
+}
@@ -0,0 +1,204 @@
+package websocket
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Magic value that's appended to the key (https://www.rfc-editor.org/rfc/rfc6455#section-1.3)
+const guid = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+func acceptKey(key string) string {
+	hash := sha1.Sum([]byte(key + guid))
+	return base64.StdEncoding.EncodeToString(hash[:])
+}
+
+// HandshakeError is returned when the request can't be upgraded
+type HandshakeError struct {
+	Status  int
+	Message string
+}
+
+func (e *HandshakeError) Error() string {
+	return "websocket: " + e.Message
+}
+
+// StatusCode to respond with
+func (e *HandshakeError) StatusCode() int {
+	return e.Status
+}
+
+// Upgrade the request to a WebSocket connection. Requests from browsers on
+// other origins are rejected. When Upgrade fails, nothing has been written, so
+// it's up to the caller to respond with the error's status code.
+func Upgrade(w http.ResponseWriter, r *http.Request) (*Conn, error) {
+	if r.Method != http.MethodGet {
+		return nil, &HandshakeError{http.StatusMethodNotAllowed, "handshake must be a GET request"}
+	}
+	if !headerContains(r.Header, "Connection", "upgrade") || !headerContains(r.Header, "Upgrade", "websocket") {
+		return nil, &HandshakeError{http.StatusBadRequest, "not a websocket handshake"}
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		return nil, &HandshakeError{http.StatusUpgradeRequired, "unsupported version"}
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" {
+		return nil, &HandshakeError{http.StatusBadRequest, "missing Sec-WebSocket-Key"}
+	}
+	if !sameOrigin(r) {
+		return nil, &HandshakeError{http.StatusForbidden, "origin not allowed"}
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		return nil, &HandshakeError{http.StatusInternalServerError, fmt.Sprintf("%T doesn't support hijacking", w)}
+	}
+	conn, brw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, &HandshakeError{http.StatusInternalServerError, err.Error()}
+	}
+	// Clear any deadlines set by the server
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	response := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + acceptKey(key) + "\r\n\r\n"
+	if _, err := conn.Write([]byte(response)); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return newConn(conn, brw.Reader, false), nil
+}
+
+// Browsers always send the origin, so check that it matches the host to
+// prevent other sites from connecting on behalf of the user
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, r.Host)
+}
+
+// Check if the comma-separated header contains the token
+func headerContains(header http.Header, key, token string) bool {
+	for _, value := range header.Values(key) {
+		for _, field := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(field), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Dial a WebSocket server (e.g. ws://localhost:3000/chat)
+func Dial(ctx context.Context, rawURL string) (*Conn, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	host := u.Host
+	secure := false
+	switch u.Scheme {
+	case "ws", "http":
+		if u.Port() == "" {
+			host = net.JoinHostPort(u.Hostname(), "80")
+		}
+	case "wss", "https":
+		secure = true
+		if u.Port() == "" {
+			host = net.JoinHostPort(u.Hostname(), "443")
+		}
+	default:
+		return nil, fmt.Errorf("websocket: unsupported scheme %q in %q", u.Scheme, rawURL)
+	}
+	dialer := new(net.Dialer)
+	conn, err := dialer.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return nil, err
+	}
+	if secure {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: u.Hostname()})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		conn = tlsConn
+	}
+	return NewClient(ctx, conn, rawURL)
+}
+
+// NewClient performs the client handshake over an existing connection. This
+// is useful for connecting over unix domain sockets.
+func NewClient(ctx context.Context, conn net.Conn, rawURL string) (*Conn, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	var nonce [16]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(nonce[:])
+	req := &http.Request{
+		Method:     http.MethodGet,
+		URL:        u,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Host:       u.Host,
+		Header: http.Header{
+			"Upgrade":               {"websocket"},
+			"Connection":            {"Upgrade"},
+			"Sec-WebSocket-Key":     {key},
+			"Sec-WebSocket-Version": {"13"},
+		},
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	br := bufio.NewReader(conn)
+	res, err := http.ReadResponse(br, req)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusSwitchingProtocols {
+		conn.Close()
+		return nil, fmt.Errorf("websocket: unable to connect to %q. Got status %d", rawURL, res.StatusCode)
+	}
+	if res.Header.Get("Sec-WebSocket-Accept") != acceptKey(key) {
+		conn.Close()
+		return nil, fmt.Errorf("websocket: invalid Sec-WebSocket-Accept from %q", rawURL)
+	}
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return newConn(conn, br, true), nil
+}
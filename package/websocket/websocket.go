@@ -0,0 +1,342 @@
+package websocket
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"unicode/utf8"
+)
+
+// Type of message
+type Type byte
+
+const (
+	Text   Type = 1
+	Binary Type = 2
+)
+
+// Opcodes of the frames (https://www.rfc-editor.org/rfc/rfc6455#section-5.2)
+const (
+	opContinuation byte = 0x0
+	opText         byte = 0x1
+	opBinary       byte = 0x2
+	opClose        byte = 0x8
+	opPing         byte = 0x9
+	opPong         byte = 0xa
+)
+
+// Close status codes (https://www.rfc-editor.org/rfc/rfc6455#section-7.4.1)
+const (
+	StatusNormal        = 1000
+	StatusGoingAway     = 1001
+	StatusProtocolError = 1002
+	StatusNoStatus      = 1005
+	StatusInvalidData   = 1007
+	StatusTooBig        = 1009
+	StatusInternalError = 1011
+)
+
+// DefaultMaxMessageSize is the largest message that's read by default
+const DefaultMaxMessageSize = 16 << 20
+
+// CloseError is returned from Read when the peer closes the connection with a
+// status other than StatusNormal or StatusGoingAway. Normal closures return
+// io.EOF.
+type CloseError struct {
+	Code   int
+	Reason string
+}
+
+func (e *CloseError) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("websocket: closed with status %d", e.Code)
+	}
+	return fmt.Sprintf("websocket: closed with status %d. %s", e.Code, e.Reason)
+}
+
+// ErrClosed is returned when using a connection that's already been closed
+var ErrClosed = errors.New("websocket: connection closed")
+
+func newConn(conn net.Conn, br *bufio.Reader, client bool) *Conn {
+	return &Conn{
+		MaxMessageSize: DefaultMaxMessageSize,
+		conn:           conn,
+		br:             br,
+		client:         client,
+	}
+}
+
+// Conn is a WebSocket connection. Reads must happen from one goroutine at a
+// time, but writes and Close are safe to call concurrently.
+type Conn struct {
+	// MaxMessageSize is the largest message that will be read. Larger messages
+	// close the connection.
+	MaxMessageSize int64
+
+	conn   net.Conn
+	br     *bufio.Reader
+	client bool // Clients mask their frames
+
+	mu     sync.Mutex // Guards writes and closed
+	closed bool
+}
+
+// Read the next message. Pings are answered while reading.
+func (c *Conn) Read() (Type, []byte, error) {
+	var message []byte
+	var messageType Type
+	for {
+		fin, op, payload, err := c.readFrame()
+		if err != nil {
+			return 0, nil, err
+		}
+		switch op {
+		case opPing:
+			if err := c.writeFrame(opPong, payload); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case opPong:
+			continue
+		case opClose:
+			return 0, nil, c.readClose(payload)
+		case opText, opBinary:
+			if messageType != 0 {
+				return 0, nil, c.fail(StatusProtocolError, "expected a continuation frame")
+			}
+			messageType = Type(op)
+		case opContinuation:
+			if messageType == 0 {
+				return 0, nil, c.fail(StatusProtocolError, "unexpected continuation frame")
+			}
+		default:
+			return 0, nil, c.fail(StatusProtocolError, fmt.Sprintf("unknown opcode %d", op))
+		}
+		if int64(len(message)+len(payload)) > c.MaxMessageSize {
+			return 0, nil, c.fail(StatusTooBig, "message too big")
+		}
+		message = append(message, payload...)
+		if !fin {
+			continue
+		}
+		if messageType == Text && !utf8.Valid(message) {
+			return 0, nil, c.fail(StatusInvalidData, "invalid utf-8 in text message")
+		}
+		return messageType, message, nil
+	}
+}
+
+// ReadJSON reads the next message into v
+func (c *Conn) ReadJSON(v interface{}) error {
+	_, data, err := c.Read()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// Write a message
+func (c *Conn) Write(t Type, data []byte) error {
+	switch t {
+	case Text, Binary:
+		return c.writeFrame(byte(t), data)
+	default:
+		return fmt.Errorf("websocket: unable to write message type %d", t)
+	}
+}
+
+// WriteJSON writes v as a text message
+func (c *Conn) WriteJSON(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.writeFrame(opText, data)
+}
+
+// Close the connection normally
+func (c *Conn) Close() error {
+	return c.CloseWith(StatusNormal, "")
+}
+
+// CloseWith sends the status code and reason to the peer, then closes the
+// connection. Reasons longer than 123 bytes are truncated.
+func (c *Conn) CloseWith(code int, reason string) error {
+	if len(reason) > 123 {
+		// Cut at the start of a rune, so the reason stays valid UTF-8
+		end := 123
+		for end > 0 && !utf8.RuneStart(reason[end]) {
+			end--
+		}
+		reason = reason[:end]
+	}
+	payload := make([]byte, 2, 2+len(reason))
+	binary.BigEndian.PutUint16(payload, uint16(code))
+	payload = append(payload, reason...)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil
+	}
+	// The peer may already be gone, so we close regardless
+	c.writeFrameLocked(opClose, payload)
+	c.closed = true
+	return c.conn.Close()
+}
+
+// Reply to the peer's close frame and close the connection
+func (c *Conn) readClose(payload []byte) error {
+	err := &CloseError{Code: StatusNoStatus}
+	if len(payload) >= 2 {
+		err.Code = int(binary.BigEndian.Uint16(payload))
+		err.Reason = string(payload[2:])
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.closed {
+		c.writeFrameLocked(opClose, payload[:min(len(payload), 2)])
+		c.closed = true
+		c.conn.Close()
+	}
+	switch err.Code {
+	case StatusNormal, StatusGoingAway, StatusNoStatus:
+		return io.EOF
+	default:
+		return err
+	}
+}
+
+// Close the connection because the peer broke the protocol
+func (c *Conn) fail(code int, reason string) error {
+	c.CloseWith(code, reason)
+	return &CloseError{Code: code, Reason: reason}
+}
+
+func (c *Conn) readFrame() (fin bool, op byte, payload []byte, err error) {
+	var header [2]byte
+	if _, err := io.ReadFull(c.br, header[:]); err != nil {
+		return false, 0, nil, c.readError(err)
+	}
+	fin = header[0]&0x80 != 0
+	op = header[0] & 0x0f
+	if header[0]&0x70 != 0 {
+		return false, 0, nil, c.fail(StatusProtocolError, "reserved bits are set")
+	}
+	masked := header[1]&0x80 != 0
+	// Clients must mask their frames and servers must not
+	if masked == c.client {
+		return false, 0, nil, c.fail(StatusProtocolError, "invalid frame mask")
+	}
+	length := uint64(header[1] & 0x7f)
+	isControl := op&0x8 != 0
+	if isControl && (!fin || length > 125) {
+		return false, 0, nil, c.fail(StatusProtocolError, "invalid control frame")
+	}
+	switch length {
+	case 126:
+		var extended [2]byte
+		if _, err := io.ReadFull(c.br, extended[:]); err != nil {
+			return false, 0, nil, c.readError(err)
+		}
+		length = uint64(binary.BigEndian.Uint16(extended[:]))
+	case 127:
+		var extended [8]byte
+		if _, err := io.ReadFull(c.br, extended[:]); err != nil {
+			return false, 0, nil, c.readError(err)
+		}
+		length = binary.BigEndian.Uint64(extended[:])
+	}
+	if length > uint64(c.MaxMessageSize) {
+		return false, 0, nil, c.fail(StatusTooBig, "message too big")
+	}
+	var mask [4]byte
+	if masked {
+		if _, err := io.ReadFull(c.br, mask[:]); err != nil {
+			return false, 0, nil, c.readError(err)
+		}
+	}
+	payload = make([]byte, length)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		return false, 0, nil, c.readError(err)
+	}
+	if masked {
+		maskBytes(mask, payload)
+	}
+	return fin, op, payload, nil
+}
+
+// Reads fail with ErrClosed after we've closed the connection
+func (c *Conn) readError(err error) error {
+	c.mu.Lock()
+	closed := c.closed
+	c.mu.Unlock()
+	if closed {
+		return ErrClosed
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return io.EOF
+	}
+	return err
+}
+
+func (c *Conn) writeFrame(op byte, payload []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return ErrClosed
+	}
+	return c.writeFrameLocked(op, payload)
+}
+
+func (c *Conn) writeFrameLocked(op byte, payload []byte) error {
+	frame := make([]byte, 0, 14+len(payload))
+	frame = append(frame, 0x80|op)
+	var maskBit byte
+	if c.client {
+		maskBit = 0x80
+	}
+	switch length := len(payload); {
+	case length <= 125:
+		frame = append(frame, maskBit|byte(length))
+	case length <= 0xffff:
+		frame = append(frame, maskBit|126, 0, 0)
+		binary.BigEndian.PutUint16(frame[2:], uint16(length))
+	default:
+		frame = append(frame, maskBit|127, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(frame[2:], uint64(length))
+	}
+	if !c.client {
+		frame = append(frame, payload...)
+		_, err := c.conn.Write(frame)
+		return err
+	}
+	var mask [4]byte
+	if _, err := rand.Read(mask[:]); err != nil {
+		return err
+	}
+	frame = append(frame, mask[:]...)
+	start := len(frame)
+	frame = append(frame, payload...)
+	maskBytes(mask, frame[start:])
+	_, err := c.conn.Write(frame)
+	return err
+}
+
+func maskBytes(mask [4]byte, b []byte) {
+	for i := range b {
+		b[i] ^= mask[i%4]
+	}
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
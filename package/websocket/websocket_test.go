@@ -0,0 +1,231 @@
+package websocket_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/livebud/bud/internal/is"
+	"github.com/livebud/bud/package/websocket"
+)
+
+func wsURL(server *httptest.Server) string {
+	return "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+// Echo messages back until the client closes
+func echo(t testing.TB) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Upgrade(w, r)
+		if err != nil {
+			http.Error(w, err.Error(), err.(*websocket.HandshakeError).StatusCode())
+			return
+		}
+		defer conn.Close()
+		for {
+			messageType, message, err := conn.Read()
+			if err != nil {
+				if !errors.Is(err, io.EOF) {
+					t.Error(err)
+				}
+				return
+			}
+			if err := conn.Write(messageType, message); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	})
+}
+
+func TestEcho(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	server := httptest.NewServer(echo(t))
+	defer server.Close()
+	conn, err := websocket.Dial(ctx, wsURL(server))
+	is.NoErr(err)
+	defer conn.Close()
+	is.NoErr(conn.Write(websocket.Text, []byte("hello")))
+	messageType, message, err := conn.Read()
+	is.NoErr(err)
+	is.Equal(messageType, websocket.Text)
+	is.Equal(string(message), "hello")
+	// Large binary messages use the extended length
+	large := []byte(strings.Repeat("a", 70000))
+	is.NoErr(conn.Write(websocket.Binary, large))
+	messageType, message, err = conn.Read()
+	is.NoErr(err)
+	is.Equal(messageType, websocket.Binary)
+	is.Equal(len(message), len(large))
+	is.NoErr(conn.Close())
+	// Using a closed connection fails
+	err = conn.Write(websocket.Text, []byte("hello"))
+	is.True(errors.Is(err, websocket.ErrClosed))
+}
+
+func TestJSON(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	server := httptest.NewServer(echo(t))
+	defer server.Close()
+	conn, err := websocket.Dial(ctx, wsURL(server))
+	is.NoErr(err)
+	defer conn.Close()
+	type message struct {
+		Body string `json:"body"`
+	}
+	is.NoErr(conn.WriteJSON(&message{"hi"}))
+	var out message
+	is.NoErr(conn.ReadJSON(&out))
+	is.Equal(out.Body, "hi")
+}
+
+func TestCloseWith(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Upgrade(w, r)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		conn.CloseWith(websocket.StatusInternalError, "unable to load room")
+	}))
+	defer server.Close()
+	conn, err := websocket.Dial(ctx, wsURL(server))
+	is.NoErr(err)
+	_, _, err = conn.Read()
+	var closeErr *websocket.CloseError
+	is.True(errors.As(err, &closeErr))
+	is.Equal(closeErr.Code, websocket.StatusInternalError)
+	is.Equal(closeErr.Reason, "unable to load room")
+	_, _, err = conn.Read()
+	is.True(errors.Is(err, websocket.ErrClosed))
+}
+
+func TestCloseWithLongReason(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Upgrade(w, r)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		// 124 bytes, where the 123rd byte is in the middle of a rune
+		conn.CloseWith(websocket.StatusInternalError, strings.Repeat("é", 62))
+	}))
+	defer server.Close()
+	conn, err := websocket.Dial(ctx, wsURL(server))
+	is.NoErr(err)
+	_, _, err = conn.Read()
+	var closeErr *websocket.CloseError
+	is.True(errors.As(err, &closeErr))
+	is.Equal(closeErr.Code, websocket.StatusInternalError)
+	is.Equal(closeErr.Reason, strings.Repeat("é", 61))
+}
+
+func TestNormalClose(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Upgrade(w, r)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		conn.Close()
+	}))
+	defer server.Close()
+	conn, err := websocket.Dial(ctx, wsURL(server))
+	is.NoErr(err)
+	_, _, err = conn.Read()
+	is.Equal(err, io.EOF)
+}
+
+func TestHandshakeErrors(t *testing.T) {
+	is := is.New(t)
+	server := httptest.NewServer(echo(t))
+	defer server.Close()
+	// Plain requests aren't upgraded
+	res, err := http.Get(server.URL)
+	is.NoErr(err)
+	res.Body.Close()
+	is.Equal(res.StatusCode, http.StatusBadRequest)
+	// Unsupported versions
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	is.NoErr(err)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	req.Header.Set("Sec-WebSocket-Version", "8")
+	res, err = http.DefaultClient.Do(req)
+	is.NoErr(err)
+	res.Body.Close()
+	is.Equal(res.StatusCode, http.StatusUpgradeRequired)
+	is.Equal(res.Header.Get("Sec-WebSocket-Version"), "13")
+	// Other origins are forbidden
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Origin", "https://evil.com")
+	res, err = http.DefaultClient.Do(req)
+	is.NoErr(err)
+	res.Body.Close()
+	is.Equal(res.StatusCode, http.StatusForbidden)
+}
+
+func TestUpgradeResponse(t *testing.T) {
+	is := is.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Upgrade(w, r)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		conn.Close()
+	}))
+	defer server.Close()
+	// Example handshake from the RFC
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	is.NoErr(err)
+	req.Header.Set("Connection", "keep-alive, Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Origin", server.URL)
+	res, err := http.DefaultClient.Do(req)
+	is.NoErr(err)
+	defer res.Body.Close()
+	is.Equal(res.StatusCode, http.StatusSwitchingProtocols)
+	is.Equal(res.Header.Get("Sec-WebSocket-Accept"), "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=")
+}
+
+func TestMaxMessageSize(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	read := make(chan error, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Upgrade(w, r)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		conn.MaxMessageSize = 10
+		_, _, err = conn.Read()
+		read <- err
+	}))
+	defer server.Close()
+	conn, err := websocket.Dial(ctx, wsURL(server))
+	is.NoErr(err)
+	is.NoErr(conn.Write(websocket.Text, []byte("this is too long")))
+	var closeErr *websocket.CloseError
+	is.True(errors.As(<-read, &closeErr))
+	is.Equal(closeErr.Code, websocket.StatusTooBig)
+	_, _, err = conn.Read()
+	is.True(errors.As(err, &closeErr))
+	is.Equal(closeErr.Code, websocket.StatusTooBig)
+}